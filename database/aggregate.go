@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"github.com/andrewpillar/query"
+)
+
+// aggregate builds SELECT exprs FROM (<select with opts>) AS t, so the aggregate honours every option that can
+// be given to Select including ORDER BY, LIMIT and OFFSET. suffix is appended as is, e.g. GROUP BY.
+func (p PosgresDB[M]) aggregate(ctx context.Context, exprs string, suffix string, opts ...query.Option) (string, []any) {
+	sql, args := p.selectQuery(ctx, query.Columns("*"), opts...)
+
+	sql = "SELECT " + exprs + " FROM (" + sql + ") AS t"
+	if suffix != "" {
+		sql += " " + suffix
+	}
+	return sql, args
+}
+
+// Count returns the number of rows matching opts.
+func (p PosgresDB[M]) Count(ctx context.Context, opts ...query.Option) (int64, error) {
+	return retry(ctx, p.retry, func(ctx context.Context) (int64, error) {
+		return p.count(ctx, p.reader(ctx), opts...)
+	})
+}
+
+func (p PosgresDB[M]) count(ctx context.Context, db querier, opts ...query.Option) (int64, error) {
+	sql, args := p.aggregate(ctx, "COUNT(*)", "", opts...)
+
+	var n int64
+	err := db.QueryRow(ctx, sql, args...).Scan(&n)
+	return n, err
+}
+
+// Exists returns whether any row matches opts.
+func (p PosgresDB[M]) Exists(ctx context.Context, opts ...query.Option) (bool, error) {
+	return retry(ctx, p.retry, func(ctx context.Context) (bool, error) {
+		return p.exists(ctx, p.reader(ctx), opts...)
+	})
+}
+
+func (p PosgresDB[M]) exists(ctx context.Context, db querier, opts ...query.Option) (bool, error) {
+	sql, args := p.selectQuery(ctx, query.Columns("1"), opts...)
+
+	var ok bool
+	err := db.QueryRow(ctx, "SELECT EXISTS ("+sql+")", args...).Scan(&ok)
+	return ok, err
+}
+
+// scalar runs the aggregate fn over col and scans the result, ok is false when the result is NULL.
+func scalar[T any, M Model](ctx context.Context, p PosgresDB[M], fn string, col string, opts ...query.Option) (T, bool, error) {
+	var zero T
+
+	sql, args := p.aggregate(ctx, fn+"("+col+")", "", opts...)
+
+	v, err := retry(ctx, p.retry, func(ctx context.Context) (*T, error) {
+		var v *T
+		err := p.reader(ctx).QueryRow(ctx, sql, args...).Scan(&v)
+		return v, err
+	})
+	if err != nil || v == nil {
+		return zero, false, err
+	}
+	return *v, true, nil
+}
+
+// Sum returns the sum of col over the rows matching opts, or the zero value of T if no rows match.
+func Sum[T any, M Model](ctx context.Context, p PosgresDB[M], col string, opts ...query.Option) (T, error) {
+	v, _, err := scalar[T](ctx, p, "SUM", col, opts...)
+	return v, err
+}
+
+// Min returns the smallest value of col over the rows matching opts, ok is false if no rows match.
+func Min[T any, M Model](ctx context.Context, p PosgresDB[M], col string, opts ...query.Option) (T, bool, error) {
+	return scalar[T](ctx, p, "MIN", col, opts...)
+}
+
+// Max returns the largest value of col over the rows matching opts, ok is false if no rows match.
+func Max[T any, M Model](ctx context.Context, p PosgresDB[M], col string, opts ...query.Option) (T, bool, error) {
+	return scalar[T](ctx, p, "MAX", col, opts...)
+}
+
+// GroupBy returns the number of rows matching opts for every distinct value of col. NULL values are counted
+// under the zero value of K.
+func GroupBy[K comparable, M Model](ctx context.Context, p PosgresDB[M], col string, opts ...query.Option) (map[K]int64, error) {
+	sql, args := p.aggregate(ctx, col+", COUNT(*)", "GROUP BY "+col, opts...)
+
+	return retry(ctx, p.retry, func(ctx context.Context) (map[K]int64, error) {
+		return groupBy[K](ctx, p.reader(ctx), sql, args)
+	})
+}
+
+func groupBy[K comparable](ctx context.Context, db querier, sql string, args []any) (map[K]int64, error) {
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	counts := make(map[K]int64)
+
+	for rows.Next() {
+		var key *K
+		var n int64
+
+		if err = rows.Scan(&key, &n); err != nil {
+			return nil, err
+		}
+
+		var k K
+		if key != nil {
+			k = *key
+		}
+		counts[k] += n
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
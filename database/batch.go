@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/andrewpillar/query"
+	"github.com/jackc/pgx/v4"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maxParams is the number of bind parameters postgres accepts in a single statement.
+const maxParams = 65535
+
+// columns returns the sorted column names of the given params.
+func columns(params map[string]any) []string {
+	cols := make([]string, 0, len(params))
+	for k := range params {
+		cols = append(cols, k)
+	}
+	sort.Strings(cols)
+	return cols
+}
+
+// paramRows returns the values of every model ordered by cols, all models must return the same set of params.
+func paramRows[M Model](models []M, cols []string, paramsOf func(M) map[string]any) ([][]any, error) {
+	rows := make([][]any, 0, len(models))
+
+	for i, m := range models {
+		params := paramsOf(m)
+		if len(params) != len(cols) {
+			return nil, fmt.Errorf("model %d has %d params, expected %d", i, len(params), len(cols))
+		}
+
+		vals := make([]any, 0, len(cols))
+		for _, col := range cols {
+			v, ok := params[col]
+			if !ok {
+				return nil, fmt.Errorf("model %d is missing param %q", i, col)
+			}
+			vals = append(vals, v)
+		}
+		rows = append(rows, vals)
+	}
+	return rows, nil
+}
+
+// identifier splits a possibly schema qualified table name for use with pgx.
+func identifier(table string) pgx.Identifier {
+	return pgx.Identifier(strings.Split(table, "."))
+}
+
+// CreateMany bulk loads the models using COPY and returns the number of rows copied. Primary keys generated by
+// the database are not scanned back into the models, use InsertMany for that.
+func (p PosgresDB[M]) CreateMany(ctx context.Context, models []M) (int64, error) {
+	return p.createMany(ctx, p.conn(ctx), models)
+}
+
+func (p PosgresDB[M]) createMany(ctx context.Context, db querier, models []M) (int64, error) {
+	if len(models) == 0 {
+		return 0, nil
+	}
+
+	t := now()
+	for _, m := range models {
+		touchCreated(m, t)
+	}
+
+	cols := columns(p.params(models[0]))
+
+	rows, err := paramRows(models, cols, p.params)
+	if err != nil {
+		return 0, err
+	}
+	return db.CopyFrom(ctx, identifier(p.table), cols, pgx.CopyFromRows(rows))
+}
+
+// InsertMany creates the models using multi-row INSERT ... RETURNING statements and scans each returned row back
+// into its model, so columns set by the database are read back. Large slices are split into several statements to
+// stay under the bind parameter limit.
+//
+// When the primary key is one of the params and every model has a distinct one before the insert, e.g. a uuid set
+// by the caller, the returned rows are matched to the models by that key. Otherwise they are matched by position, which relies on postgres
+// returning the rows of a multi-row VALUES insert in the order they were given. Current versions do, but it is an
+// implementation detail that is not guaranteed, use Create for each model when that is not acceptable.
+func (p PosgresDB[M]) InsertMany(ctx context.Context, models []M) error {
+	return p.insertMany(ctx, p.conn(ctx), models)
+}
+
+func (p PosgresDB[M]) insertMany(ctx context.Context, db querier, models []M) error {
+	if len(models) == 0 {
+		return nil
+	}
+
+	t := now()
+	for _, m := range models {
+		touchCreated(m, t)
+	}
+
+	cols := columns(p.params(models[0]))
+
+	rows, err := paramRows(models, cols, p.params)
+	if err != nil {
+		return err
+	}
+
+	size := len(rows)
+	if len(cols) > 0 && size*len(cols) > maxParams {
+		size = maxParams / len(cols)
+	}
+
+	for start := 0; start < len(rows); start += size {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if err := p.insertChunk(ctx, db, cols, rows[start:end], models[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p PosgresDB[M]) insertChunk(ctx context.Context, db querier, cols []string, vals [][]any, models []M) error {
+	opts := make([]query.Option, 0, len(vals)+1)
+	for _, v := range vals {
+		opts = append(opts, query.Values(v...))
+	}
+	opts = append(opts, query.Returning("*"))
+
+	q := query.Insert(p.table, query.Columns(cols...), opts...)
+
+	rows, err := db.Query(ctx, q.Build(), q.Args()...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	byKey, keyed := keyIndex(models, cols)
+
+	i := 0
+	for rows.Next() {
+		if i >= len(models) {
+			return fmt.Errorf("insert returned more rows than models")
+		}
+
+		flds := fields(rows)
+		m := models[i]
+
+		if keyed {
+			// scan the row once to read its key, then again into the model with that key.
+			row := p.new()
+			if err = row.Scan(flds, rows.Scan); err != nil {
+				return err
+			}
+
+			_, key := row.Primary()
+
+			idx, ok := byKey[key]
+			if !ok {
+				return fmt.Errorf("insert returned a row with unknown primary key %v", key)
+			}
+			m = models[idx]
+		}
+
+		if err = m.Scan(flds, rows.Scan); err != nil {
+			return err
+		}
+		i++
+	}
+	return rows.Err()
+}
+
+// keyIndex maps the primary key of every model to its index. ok is false unless the primary key is one of the
+// inserted cols and every model has a distinct, comparable and non-zero key, a key left out of the params is set
+// by the database and the returned rows would not carry the key of the model.
+func keyIndex[M Model](models []M, cols []string) (map[any]int, bool) {
+	pk, _ := models[0].Primary()
+
+	inserted := false
+	for _, col := range cols {
+		if col == pk {
+			inserted = true
+			break
+		}
+	}
+	if !inserted {
+		return nil, false
+	}
+
+	byKey := make(map[any]int, len(models))
+
+	for i, m := range models {
+		_, key := m.Primary()
+		if key == nil {
+			return nil, false
+		}
+
+		v := reflect.ValueOf(key)
+		if !v.Type().Comparable() || v.IsZero() {
+			return nil, false
+		}
+
+		if _, ok := byKey[key]; ok {
+			return nil, false
+		}
+		byKey[key] = i
+	}
+	return byKey, true
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"github.com/andrewpillar/query"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// ErrUnfiltered is returned by UpdateWhere and DeleteWhere when no WHERE option is given, unless the context was
+// returned by AllowUnfiltered.
+var ErrUnfiltered = errors.New("refusing to update or delete every row without a WHERE clause")
+
+type allowUnfilteredKey struct{}
+
+// AllowUnfiltered returns a context under which UpdateWhere and DeleteWhere may act on every row of the table.
+func AllowUnfiltered(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowUnfilteredKey{}, true)
+}
+
+// checkFiltered returns ErrUnfiltered if opts hold no WHERE clause and ctx does not allow it.
+func checkFiltered(ctx context.Context, opts []query.Option) error {
+	if v, _ := ctx.Value(allowUnfilteredKey{}).(bool); v {
+		return nil
+	}
+
+	if !strings.Contains(query.Delete("t", opts...).Build(), " WHERE ") {
+		return ErrUnfiltered
+	}
+	return nil
+}
+
+// UpdateWhere sets the given columns on every row matching opts and returns the number of rows affected. The
+// version of Versioned models is incremented.
+func (p PosgresDB[M]) UpdateWhere(ctx context.Context, set map[string]any, opts ...query.Option) (int64, error) {
+	return p.updateWhere(ctx, p.conn(ctx), set, opts...)
+}
+
+func (p PosgresDB[M]) updateWhere(ctx context.Context, db querier, set map[string]any, opts ...query.Option) (int64, error) {
+	if len(set) == 0 {
+		return 0, errors.New("update requires at least one column to set")
+	}
+
+	if err := checkFiltered(ctx, opts); err != nil {
+		return 0, err
+	}
+
+	if col := p.tenantColumn(); col != "" {
+		if _, ok := set[col]; ok {
+			return 0, errors.New("update of a tenant scoped repository cannot set the tenant column")
+		}
+	}
+
+	stmt := make([]query.Option, 0, len(set)+2)
+
+	for _, col := range columns(set) {
+		stmt = append(stmt, query.Set(col, query.Arg(set[col])))
+	}
+
+	if v, ok := any(p.new()).(Versioned); ok {
+		col, _ := v.Version()
+		if _, ok := set[col]; !ok {
+			stmt = append(stmt, query.Set(col, query.Lit(col+" + 1")))
+		}
+	}
+
+	stmt = append(stmt, p.filter(ctx, opts)...)
+
+	q := query.Update(p.table, stmt...)
+
+	tag, err := db.Exec(ctx, q.Build(), q.Args()...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteWhere deletes every row matching opts and returns the number of rows affected. SoftDeletable models are
+// marked as deleted instead.
+func (p PosgresDB[M]) DeleteWhere(ctx context.Context, opts ...query.Option) (int64, error) {
+	return p.deleteWhere(ctx, p.conn(ctx), opts...)
+}
+
+func (p PosgresDB[M]) deleteWhere(ctx context.Context, db querier, opts ...query.Option) (int64, error) {
+	if err := checkFiltered(ctx, opts); err != nil {
+		return 0, err
+	}
+
+	if col := p.deletedAtColumn(); col != "" {
+		// rows that are already deleted keep their deleted_at, even under IncludeDeleted.
+		ctx = context.WithValue(ctx, includeDeletedKey{}, false)
+		return p.updateWhere(ctx, db, map[string]any{col: now()}, opts...)
+	}
+
+	q := query.Delete(p.table, p.filter(ctx, opts)...)
+
+	tag, err := db.Exec(ctx, q.Build(), q.Args()...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
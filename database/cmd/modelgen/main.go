@@ -0,0 +1,271 @@
+// Command modelgen generates the Primary, Scan and Params methods of database.Model from db struct tags, the
+// same tags understood by database.ReflectModel, so hot paths avoid reflection.
+/*
+//go:generate go run github.com/themodelarchitect/data/database/cmd/modelgen -type User
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/themodelarchitect/data/database"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type field struct {
+	name     string
+	column   string
+	pk       bool
+	readonly bool
+}
+
+type model struct {
+	name   string
+	fields []field
+}
+
+func main() {
+	types := flag.String("type", "", "comma separated list of struct types")
+	output := flag.String("output", "", "output file, defaults to <first type>_model.go")
+	flag.Parse()
+
+	if *types == "" {
+		log.Fatal("modelgen: -type is required")
+	}
+
+	dir := "."
+	if file := os.Getenv("GOFILE"); file != "" {
+		dir = filepath.Dir(file)
+	}
+
+	names := strings.Split(*types, ",")
+
+	src, err := generate(dir, names)
+	if err != nil {
+		log.Fatalf("modelgen: %v", err)
+	}
+
+	out := *output
+	if out == "" {
+		out = filepath.Join(dir, strings.ToLower(names[0])+"_model.go")
+	}
+
+	if err = os.WriteFile(out, src, 0644); err != nil {
+		log.Fatalf("modelgen: %v", err)
+	}
+}
+
+// generate parses the package in dir and returns the formatted source of the methods for the named types.
+func generate(dir string, names []string) ([]byte, error) {
+	fset := token.NewFileSet()
+
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for pkgName, pkg := range pkgs {
+		models := make([]model, 0, len(names))
+
+		for _, name := range names {
+			m, ok, err := findModel(pkg, name)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, fmt.Errorf("type %s not found in package %s", name, pkgName)
+			}
+			models = append(models, m)
+		}
+		return render(pkgName, models)
+	}
+	return nil, fmt.Errorf("no package found in %s", dir)
+}
+
+func findModel(pkg *ast.Package, name string) (model, bool, error) {
+	ts := findType(pkg, name)
+	if ts == nil {
+		return model{}, false, nil
+	}
+
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return model{}, false, fmt.Errorf("type %s is not a struct", name)
+	}
+
+	m := model{name: name}
+	err := collectFields(pkg, name, st, "", &m)
+	return m, true, err
+}
+
+// findType returns the spec of the named type declared in pkg, or nil.
+func findType(pkg *ast.Package, name string) *ast.TypeSpec {
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+					return ts
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// collectFields appends the columns of st to m with the rules of database.ReflectModel, the fields of an
+// untagged embedded struct are flattened and are reached through path.
+func collectFields(pkg *ast.Package, name string, st *ast.StructType, path string, m *model) error {
+	for _, f := range st.Fields.List {
+		var tag string
+		var tagged bool
+
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return err
+			}
+			tag, tagged = reflect.StructTag(s).Lookup("db")
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		names := f.Names
+
+		if len(names) == 0 {
+			ident, embedded, err := embeddedType(pkg, name, f.Type, tagged)
+			if err != nil {
+				return err
+			}
+			if embedded != nil {
+				if err = collectFields(pkg, name, embedded, path+ident.Name+".", m); err != nil {
+					return err
+				}
+				continue
+			}
+			names = []*ast.Ident{ident}
+		}
+
+		opts := strings.Split(tag, ",")
+
+		for _, ident := range names {
+			if !ident.IsExported() {
+				continue
+			}
+
+			fld := field{name: path + ident.Name, column: opts[0]}
+			if fld.column == "" {
+				fld.column = database.SnakeCase(ident.Name)
+			}
+
+			for _, opt := range opts[1:] {
+				switch opt {
+				case "pk":
+					fld.pk = true
+				case "readonly":
+					fld.readonly = true
+				}
+			}
+			m.fields = append(m.fields, fld)
+		}
+	}
+	return nil
+}
+
+// embeddedType returns the name of the embedded field of type expr, and the struct to flatten if the field is an
+// untagged struct. Only the structs declared in pkg can be flattened, those of other packages must be tagged.
+func embeddedType(pkg *ast.Package, name string, expr ast.Expr, tagged bool) (*ast.Ident, *ast.StructType, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if tagged {
+			return t, nil, nil
+		}
+
+		ts := findType(pkg, t.Name)
+		if ts == nil {
+			// a predeclared type such as error is not exported, so it is skipped as a column.
+			if !t.IsExported() {
+				return t, nil, nil
+			}
+			return nil, nil, fmt.Errorf("%s: embedded type %s not found", name, t.Name)
+		}
+		if st, ok := ts.Type.(*ast.StructType); ok {
+			return t, st, nil
+		}
+		return t, nil, nil
+	case *ast.StarExpr:
+		// a pointer is never flattened.
+		ident, _, err := embeddedType(pkg, name, t.X, true)
+		return ident, nil, err
+	case *ast.SelectorExpr:
+		if !tagged {
+			return nil, nil, fmt.Errorf("%s: embedded type %s.%s of another package cannot be flattened, tag it with db", name, t.X, t.Sel.Name)
+		}
+		return t.Sel, nil, nil
+	}
+	return nil, nil, fmt.Errorf("%s: unsupported embedded field", name)
+}
+
+func render(pkgName string, models []model) ([]byte, error) {
+	var buf bytes.Buffer
+
+	qualifier := "database."
+	if pkgName == "database" {
+		qualifier = ""
+	}
+
+	fmt.Fprintf(&buf, "// Code generated by modelgen. DO NOT EDIT.\n\npackage %s\n\n", pkgName)
+	if qualifier != "" {
+		buf.WriteString("import \"github.com/themodelarchitect/data/database\"\n\n")
+	}
+
+	for _, m := range models {
+		var pk *field
+		for i := range m.fields {
+			if m.fields[i].pk {
+				pk = &m.fields[i]
+				break
+			}
+		}
+
+		fmt.Fprintf(&buf, "func (m *%s) Primary() (string, any) {\n", m.name)
+		if pk == nil {
+			buf.WriteString("\treturn \"\", nil\n}\n\n")
+		} else {
+			fmt.Fprintf(&buf, "\treturn %q, m.%s\n}\n\n", pk.column, pk.name)
+		}
+
+		fmt.Fprintf(&buf, "func (m *%s) Scan(fields []string, scan %sScanFunc) error {\n", m.name, qualifier)
+		fmt.Fprintf(&buf, "\treturn %sScan(map[string]any{\n", qualifier)
+		for _, f := range m.fields {
+			fmt.Fprintf(&buf, "\t\t%q: &m.%s,\n", f.column, f.name)
+		}
+		buf.WriteString("\t}, fields, scan)\n}\n\n")
+
+		fmt.Fprintf(&buf, "func (m *%s) Params() map[string]any {\n\treturn map[string]any{\n", m.name)
+		for _, f := range m.fields {
+			if !f.readonly {
+				fmt.Fprintf(&buf, "\t\t%q: m.%s,\n", f.column, f.name)
+			}
+		}
+		buf.WriteString("\t}\n}\n\n")
+	}
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"github.com/themodelarchitect/data/database"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+
+	src := `package users
+
+type User struct {
+	Id        string ` + "`db:\"id,pk,readonly\"`" + `
+	FirstName string
+	Password  string ` + "`db:\"-\"`" + `
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "user.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := generate(dir, []string{"User"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := string(out)
+	for _, want := range []string{
+		`return "id", m.Id`,
+		`"first_name": &m.FirstName,`,
+		`"first_name": m.FirstName,`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Fatalf("expected %q in\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "Password") || strings.Contains(code, `"id": m.Id`) {
+		t.Fatalf("unexpected column in\n%s", code)
+	}
+}
+
+type base struct {
+	Id        string `db:"id,pk"`
+	CreatedAt string `db:",readonly"`
+}
+
+type Meta struct {
+	Plan string
+}
+
+type Account struct {
+	base
+	Meta  `db:"meta"`
+	Email string
+}
+
+func TestGenerateEmbedded(t *testing.T) {
+	dir := t.TempDir()
+
+	src := `package users
+
+type base struct {
+	Id        string ` + "`db:\"id,pk\"`" + `
+	CreatedAt string ` + "`db:\",readonly\"`" + `
+}
+
+type Meta struct {
+	Plan string
+}
+
+type Account struct {
+	base
+	Meta  ` + "`db:\"meta\"`" + `
+	Email string
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "account.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := generate(dir, []string{"Account"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the columns are aligned by gofmt.
+	code := strings.Join(strings.Fields(string(out)), " ")
+	for _, want := range []string{
+		`return "id", m.base.Id`,
+		`"created_at": &m.base.CreatedAt,`,
+		`"meta": &m.Meta,`,
+		`"meta": m.Meta,`,
+		`"email": m.Email,`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Fatalf("expected %q in\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, `"created_at": m.base.CreatedAt`) || strings.Contains(code, `"plan"`) {
+		t.Fatalf("unexpected column in\n%s", code)
+	}
+
+	// the generated params have the same columns as those reflected from the same struct.
+	for col := range database.ReflectParams(&Account{}) {
+		if !strings.Contains(code, strconv.Quote(col)+": m.") {
+			t.Fatalf("expected reflected column %q in\n%s", col, code)
+		}
+	}
+}
+
+func TestGenerateEmbeddedOtherPackage(t *testing.T) {
+	dir := t.TempDir()
+
+	src := `package users
+
+import "time"
+
+type Event struct {
+	time.Time
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "event.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := generate(dir, []string{"Event"}); err == nil {
+		t.Fatal("expected an error for an untagged embedded struct of another package")
+	}
+}
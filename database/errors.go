@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
+)
+
+// Errors shared by every backend, driver errors are mapped onto these so callers can use errors.Is regardless of
+// the store. The driver error is still available through errors.As.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrDuplicateKey = errors.New("duplicate key")
+	ErrConstraint   = errors.New("constraint violation")
+	ErrConflict     = errors.New("conflict")
+)
+
+// dbError wraps a driver error with the shared error it maps onto.
+type dbError struct {
+	kind error
+	err  error
+}
+
+func (e *dbError) Error() string {
+	return e.kind.Error() + ": " + e.err.Error()
+}
+
+func (e *dbError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *dbError) Unwrap() error {
+	return e.err
+}
+
+// postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation      = "23505"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+// mapPostgresError maps a pgx error onto the shared errors.
+func mapPostgresError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var de *dbError
+	if errors.As(err, &de) {
+		return err
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &dbError{kind: ErrNotFound, err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch {
+	case pgErr.Code == pgUniqueViolation:
+		return &dbError{kind: ErrDuplicateKey, err: err}
+	case pgErr.Code == pgSerializationFailure, pgErr.Code == pgDeadlockDetected:
+		return &dbError{kind: ErrConflict, err: err}
+	case strings.HasPrefix(pgErr.Code, "23"):
+		// class 23 is integrity constraint violations, foreign key, not null, check and exclusion.
+		return &dbError{kind: ErrConstraint, err: err}
+	}
+	return err
+}
+
+// mapMongoError maps a mongo driver error onto the shared errors.
+func mapMongoError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return &dbError{kind: ErrNotFound, err: err}
+	case mongo.IsDuplicateKeyError(err):
+		return &dbError{kind: ErrDuplicateKey, err: err}
+	}
+
+	var se mongo.ServerError
+	if errors.As(err, &se) && se.HasErrorLabel("TransientTransactionError") {
+		return &dbError{kind: ErrConflict, err: err}
+	}
+	return err
+}
+
+// errorQuerier maps the errors of every statement onto the shared errors.
+type errorQuerier struct {
+	querier
+}
+
+func (q errorQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tag, err := q.querier.Exec(ctx, sql, args...)
+	return tag, mapPostgresError(err)
+}
+
+func (q errorQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := q.querier.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapPostgresError(err)
+	}
+	return errorRows{rows}, nil
+}
+
+func (q errorQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return errorRow{q.querier.QueryRow(ctx, sql, args...)}
+}
+
+func (q errorQuerier) CopyFrom(ctx context.Context, table pgx.Identifier, cols []string, src pgx.CopyFromSource) (int64, error) {
+	n, err := q.querier.CopyFrom(ctx, table, cols, src)
+	return n, mapPostgresError(err)
+}
+
+type errorRows struct {
+	pgx.Rows
+}
+
+func (r errorRows) Err() error {
+	return mapPostgresError(r.Rows.Err())
+}
+
+func (r errorRows) Scan(dest ...any) error {
+	return mapPostgresError(r.Rows.Scan(dest...))
+}
+
+type errorRow struct {
+	pgx.Row
+}
+
+func (r errorRow) Scan(dest ...any) error {
+	return mapPostgresError(r.Row.Scan(dest...))
+}
@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PoolStats is a snapshot of the connections of a store.
+type PoolStats struct {
+	TotalConns    int32 `json:"total_conns"`
+	IdleConns     int32 `json:"idle_conns"`
+	AcquiredConns int32 `json:"acquired_conns"`
+	MaxConns      int32 `json:"max_conns"`
+}
+
+// HealthChecker is implemented by every store that can report its health.
+type HealthChecker interface {
+	// Ping checks the store can be reached, giving up when ctx is done.
+	Ping(ctx context.Context) error
+	Stats() PoolStats
+}
+
+// HealthStatus is the health of a single store.
+type HealthStatus struct {
+	Name    string        `json:"name"`
+	Healthy bool          `json:"healthy"`
+	Error   string        `json:"error,omitempty"`
+	Latency time.Duration `json:"latency"`
+	Stats   PoolStats     `json:"stats"`
+}
+
+// HealthReport is the health of every registered store, Healthy is true only if all of them are.
+type HealthReport struct {
+	Healthy bool           `json:"healthy"`
+	Stores  []HealthStatus `json:"stores"`
+}
+
+// Health aggregates the health of several stores, e.g. for a readiness probe.
+type Health struct {
+	timeout time.Duration
+	mu      sync.RWMutex
+	stores  map[string]HealthChecker
+}
+
+// NewHealth returns a Health that gives every store timeout to answer a ping.
+func NewHealth(timeout time.Duration) *Health {
+	return &Health{timeout: timeout, stores: make(map[string]HealthChecker)}
+}
+
+// Register adds a store under name, replacing any store registered under the same name.
+func (h *Health) Register(name string, store HealthChecker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stores[name] = store
+}
+
+// Check pings every registered store concurrently.
+func (h *Health) Check(ctx context.Context) HealthReport {
+	h.mu.RLock()
+	stores := make(map[string]HealthChecker, len(h.stores))
+	for name, store := range h.stores {
+		stores[name] = store
+	}
+	h.mu.RUnlock()
+
+	report := HealthReport{Healthy: true, Stores: make([]HealthStatus, 0, len(stores))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, store := range stores {
+		wg.Add(1)
+
+		go func(name string, store HealthChecker) {
+			defer wg.Done()
+
+			status := h.check(ctx, name, store)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Stores = append(report.Stores, status)
+			report.Healthy = report.Healthy && status.Healthy
+		}(name, store)
+	}
+	wg.Wait()
+
+	sort.Slice(report.Stores, func(i, j int) bool {
+		return report.Stores[i].Name < report.Stores[j].Name
+	})
+	return report
+}
+
+func (h *Health) check(ctx context.Context, name string, store HealthChecker) HealthStatus {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := store.Ping(ctx)
+
+	status := HealthStatus{
+		Name:    name,
+		Healthy: err == nil,
+		Latency: time.Since(start),
+		Stats:   store.Stats(),
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// Stats returns the connections of the primary pool.
+func (p PosgresDB[M]) Stats() PoolStats {
+	stat := p.Stat()
+	return PoolStats{
+		TotalConns:    stat.TotalConns(),
+		IdleConns:     stat.IdleConns(),
+		AcquiredConns: stat.AcquiredConns(),
+		MaxConns:      stat.MaxConns(),
+	}
+}
+
+// Close closes the primary and replica pools, waiting for acquired connections to be released.
+func (p PosgresDB[M]) Close() {
+	if p.replicas != nil {
+		p.replicas.close()
+	}
+	p.Pool.Close()
+}
+
+// Shutdown closes the repository like Close, but gives up waiting for acquired connections when ctx is done.
+func (p PosgresDB[M]) Shutdown(ctx context.Context) error {
+	return shutdown(ctx, p.Close)
+}
+
+// Ping checks the connection to the primary.
+func (m *MongoDB[T]) Ping(ctx context.Context) error {
+	return mapMongoError(m.Client.Ping(ctx, nil))
+}
+
+// Stats reports the sessions in progress as acquired connections, the driver does not expose its pool otherwise.
+func (m *MongoDB[T]) Stats() PoolStats {
+	return PoolStats{AcquiredConns: int32(m.Client.NumberSessionsInProgress())}
+}
+
+// Close disconnects the client, waiting for operations in progress to finish.
+func (m *MongoDB[T]) Close() {
+	_ = m.Client.Disconnect(context.Background())
+}
+
+// Shutdown disconnects the client, giving up waiting for operations in progress when ctx is done.
+func (m *MongoDB[T]) Shutdown(ctx context.Context) error {
+	return m.Client.Disconnect(ctx)
+}
+
+// Ping always succeeds, the store lives in memory.
+func (kv *InMemoryKV[K, V]) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Stats returns no connections, the store lives in memory.
+func (kv *InMemoryKV[K, V]) Stats() PoolStats {
+	return PoolStats{}
+}
+
+// shutdown runs fn in the background and waits for it to return or for ctx to be done.
+func shutdown(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+
+	go func() {
+		fn()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
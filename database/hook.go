@@ -0,0 +1,231 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// QueryEvent describes a statement run by a repository.
+type QueryEvent struct {
+	SQL  string
+	Args []any
+	// Start is when the statement was sent, Duration and RowsAffected are set once it has finished. For queries
+	// returning rows the statement finishes once the rows are closed.
+	Start        time.Time
+	Duration     time.Duration
+	RowsAffected int64
+	Err          error
+}
+
+// QueryHook is called before and after every statement a repository runs. BeforeQuery may return a derived
+// context, e.g. holding a tracing span, which is passed on to the statement and to AfterQuery.
+type QueryHook interface {
+	BeforeQuery(ctx context.Context, e *QueryEvent) context.Context
+	AfterQuery(ctx context.Context, e *QueryEvent)
+}
+
+// WithHooks adds hooks called around every statement run by the repository, including those run in transactions.
+func WithHooks(hooks ...QueryHook) PostgresOption {
+	return func(o *postgresOptions) {
+		o.hooks = append(o.hooks, hooks...)
+	}
+}
+
+// Redactor returns the value logged for the i-th argument of a statement.
+type Redactor func(i int, arg any) any
+
+// RedactAll hides every argument, it is the Redactor of the hooks when none is set.
+func RedactAll(i int, arg any) any {
+	return "[redacted]"
+}
+
+// RedactNone logs the arguments as is, they may hold passwords or personal data.
+func RedactNone(i int, arg any) any {
+	return arg
+}
+
+func redactArgs(args []any, redact Redactor) []any {
+	if redact == nil {
+		redact = RedactAll
+	}
+
+	redacted := make([]any, len(args))
+	for i, arg := range args {
+		redacted[i] = redact(i, arg)
+	}
+	return redacted
+}
+
+// SlogHook logs every statement at debug level, and failed statements at error level.
+type SlogHook struct {
+	Logger *slog.Logger
+	// Redact is applied to the arguments before they are logged, RedactAll when nil.
+	Redact Redactor
+}
+
+func (h SlogHook) BeforeQuery(ctx context.Context, e *QueryEvent) context.Context {
+	return ctx
+}
+
+func (h SlogHook) AfterQuery(ctx context.Context, e *QueryEvent) {
+	logger := h.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	attrs := []any{
+		slog.String("sql", e.SQL),
+		slog.Any("args", redactArgs(e.Args, h.Redact)),
+		slog.Duration("duration", e.Duration),
+		slog.Int64("rows", e.RowsAffected),
+	}
+
+	if e.Err != nil {
+		logger.ErrorContext(ctx, "query failed", append(attrs, slog.Any("error", e.Err))...)
+		return
+	}
+	logger.DebugContext(ctx, "query", attrs...)
+}
+
+// SlowQueryHook reports statements taking longer than Threshold.
+type SlowQueryHook struct {
+	Threshold time.Duration
+	// Report is called for every slow statement, when nil the statement is logged at warn level with slog.
+	Report func(ctx context.Context, e QueryEvent)
+	// Redact is applied to the arguments before they are reported, RedactAll when nil.
+	Redact Redactor
+}
+
+func (h SlowQueryHook) BeforeQuery(ctx context.Context, e *QueryEvent) context.Context {
+	return ctx
+}
+
+func (h SlowQueryHook) AfterQuery(ctx context.Context, e *QueryEvent) {
+	if e.Duration < h.Threshold {
+		return
+	}
+
+	event := *e
+	event.Args = redactArgs(e.Args, h.Redact)
+
+	if h.Report != nil {
+		h.Report(ctx, event)
+		return
+	}
+
+	slog.WarnContext(ctx, "slow query",
+		slog.String("sql", event.SQL),
+		slog.Any("args", event.Args),
+		slog.Duration("duration", event.Duration),
+		slog.Duration("threshold", h.Threshold),
+	)
+}
+
+// hookQuerier calls the hooks around every statement.
+type hookQuerier struct {
+	querier
+	hooks []QueryHook
+}
+
+func (q hookQuerier) before(ctx context.Context, sql string, args []any) (context.Context, *QueryEvent) {
+	e := &QueryEvent{SQL: sql, Args: args, Start: time.Now()}
+	for _, h := range q.hooks {
+		ctx = h.BeforeQuery(ctx, e)
+	}
+	return ctx, e
+}
+
+func (q hookQuerier) after(ctx context.Context, e *QueryEvent, rows int64, err error) {
+	e.Duration = time.Since(e.Start)
+	e.RowsAffected = rows
+	e.Err = err
+
+	for i := len(q.hooks) - 1; i >= 0; i-- {
+		q.hooks[i].AfterQuery(ctx, e)
+	}
+}
+
+func (q hookQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	ctx, e := q.before(ctx, sql, args)
+	tag, err := q.querier.Exec(ctx, sql, args...)
+	q.after(ctx, e, tag.RowsAffected(), err)
+	return tag, err
+}
+
+func (q hookQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	ctx, e := q.before(ctx, sql, args)
+	rows, err := q.querier.Query(ctx, sql, args...)
+	if err != nil {
+		q.after(ctx, e, 0, err)
+		return nil, err
+	}
+	return &hookRows{Rows: rows, ctx: ctx, event: e, q: q}, nil
+}
+
+func (q hookQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	ctx, e := q.before(ctx, sql, args)
+	return hookRow{Row: q.querier.QueryRow(ctx, sql, args...), ctx: ctx, event: e, q: q}
+}
+
+func (q hookQuerier) CopyFrom(ctx context.Context, table pgx.Identifier, cols []string, src pgx.CopyFromSource) (int64, error) {
+	sql := fmt.Sprintf("COPY %s (%s) FROM STDIN", table.Sanitize(), strings.Join(cols, ", "))
+
+	ctx, e := q.before(ctx, sql, nil)
+	n, err := q.querier.CopyFrom(ctx, table, cols, src)
+	q.after(ctx, e, n, err)
+	return n, err
+}
+
+// hookRows calls the after hooks once the rows are closed.
+type hookRows struct {
+	pgx.Rows
+	ctx    context.Context
+	event  *QueryEvent
+	q      hookQuerier
+	closed bool
+}
+
+func (r *hookRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	// pgx closes the rows once they are exhausted.
+	r.finish()
+	return false
+}
+
+func (r *hookRows) Close() {
+	r.Rows.Close()
+	r.finish()
+}
+
+func (r *hookRows) finish() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.q.after(r.ctx, r.event, r.Rows.CommandTag().RowsAffected(), r.Rows.Err())
+}
+
+type hookRow struct {
+	pgx.Row
+	ctx   context.Context
+	event *QueryEvent
+	q     hookQuerier
+}
+
+func (r hookRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+
+	var rows int64
+	if err == nil {
+		rows = 1
+	}
+	r.q.after(r.ctx, r.event, rows, err)
+	return err
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"github.com/andrewpillar/query"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// ErrStopIteration can be returned from the func given to Iterate to stop early without an error.
+var ErrStopIteration = errors.New("stop iteration")
+
+// Iterator scans one Model at a time from a result set. It must be closed once done with.
+type Iterator[M Model] struct {
+	rows   pgx.Rows
+	fields []string
+	new    func() M
+	m      M
+	err    error
+}
+
+// Next scans the next row, it returns false when there are no more rows or scanning failed.
+func (it *Iterator[M]) Next() bool {
+	if it.err != nil || !it.rows.Next() {
+		return false
+	}
+
+	if it.fields == nil {
+		it.fields = fields(it.rows)
+	}
+
+	m := it.new()
+	if err := m.Scan(it.fields, it.rows.Scan); err != nil {
+		it.err = err
+		return false
+	}
+	it.m = m
+	return true
+}
+
+// Model returns the Model scanned by the last call to Next.
+func (it *Iterator[M]) Model() M {
+	return it.m
+}
+
+// Err returns the first error encountered while iterating.
+func (it *Iterator[M]) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.rows.Err()
+}
+
+// Close releases the underlying connection, it is safe to call more than once.
+func (it *Iterator[M]) Close() {
+	it.rows.Close()
+}
+
+// Rows selects cols from the table and returns an Iterator over the result.
+func (p PosgresDB[M]) Rows(ctx context.Context, cols []string, opts ...query.Option) (*Iterator[M], error) {
+	return p.iterator(ctx, p.reader(ctx), cols, opts...)
+}
+
+func (p PosgresDB[M]) iterator(ctx context.Context, db querier, cols []string, opts ...query.Option) (*Iterator[M], error) {
+	sql, args := p.selectQuery(ctx, query.Columns(cols...), opts...)
+
+	rows, err := db.Query(ctx, sql, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	return &Iterator[M]{rows: rows, new: p.new}, nil
+}
+
+// Iterate selects cols from the table and calls fn for every Model without holding the whole result in memory.
+// Returning ErrStopIteration from fn stops the iteration early without an error.
+func (p PosgresDB[M]) Iterate(ctx context.Context, cols []string, fn func(M) error, opts ...query.Option) error {
+	return p.iterate(ctx, p.reader(ctx), cols, fn, opts...)
+}
+
+func (p PosgresDB[M]) iterate(ctx context.Context, db querier, cols []string, fn func(M) error, opts ...query.Option) error {
+	it, err := p.iterator(ctx, db, cols, opts...)
+	if err != nil {
+		return err
+	}
+
+	defer it.Close()
+
+	for it.Next() {
+		if err = fn(it.Model()); err != nil {
+			if errors.Is(err, ErrStopIteration) {
+				return nil
+			}
+			return err
+		}
+	}
+	return it.Err()
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"github.com/andrewpillar/query"
+	"github.com/jackc/pgtype"
+	"strings"
+)
+
+// JSONB stores V in a json or jsonb column. Put a JSONB in Params and a pointer to it in the struct map given to
+// Scan, NULL scans as the zero value of V with Valid false.
+type JSONB[T any] struct {
+	V     T
+	Valid bool
+}
+
+// NewJSONB returns a valid JSONB of v.
+func NewJSONB[T any](v T) JSONB[T] {
+	return JSONB[T]{V: v, Valid: true}
+}
+
+// Value marshals V, or returns NULL when not valid.
+func (j JSONB[T]) Value() (driver.Value, error) {
+	if !j.Valid {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(j.V)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (j *JSONB[T]) DecodeBinary(ci *pgtype.ConnInfo, src []byte) error {
+	// json columns are sent as the JSON text, jsonb ones are prefixed by the version byte 1 that JSON text never
+	// starts with.
+	if len(src) > 0 && src[0] != 1 {
+		var raw pgtype.JSON
+		if err := raw.DecodeBinary(ci, src); err != nil {
+			return err
+		}
+		return j.decode(pgtype.JSONB(raw))
+	}
+
+	var raw pgtype.JSONB
+	if err := raw.DecodeBinary(ci, src); err != nil {
+		return err
+	}
+	return j.decode(raw)
+}
+
+func (j *JSONB[T]) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
+	var raw pgtype.JSONB
+	if err := raw.DecodeText(ci, src); err != nil {
+		return err
+	}
+	return j.decode(raw)
+}
+
+func (j *JSONB[T]) decode(raw pgtype.JSONB) error {
+	var zero T
+
+	j.V, j.Valid = zero, raw.Status == pgtype.Present
+
+	if !j.Valid {
+		return nil
+	}
+	return json.Unmarshal(raw.Bytes, &j.V)
+}
+
+// MarshalJSON marshals V, or null when not valid, so models holding a JSONB encode as if they held V.
+func (j JSONB[T]) MarshalJSON() ([]byte, error) {
+	if !j.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(j.V)
+}
+
+// UnmarshalJSON unmarshals into V, null is not valid.
+func (j *JSONB[T]) UnmarshalJSON(b []byte) error {
+	var zero T
+
+	j.V, j.Valid = zero, string(b) != "null"
+
+	if !j.Valid {
+		return nil
+	}
+	return json.Unmarshal(b, &j.V)
+}
+
+// JSONField returns the expression of the text at path within col, e.g. JSONField("meta", "address", "city")
+// is meta->'address'->>'city'. It can be used as a column in Where or the Order options.
+/*
+users.Select(ctx, []string{"*"}, query.Where(JSONField("meta", "plan"), "=", query.Arg("pro")))
+*/
+func JSONField(col string, path ...string) string {
+	if len(path) == 0 {
+		return col
+	}
+
+	var sb strings.Builder
+	sb.WriteString(col)
+
+	for i, key := range path {
+		if i == len(path)-1 {
+			sb.WriteString("->>")
+		} else {
+			sb.WriteString("->")
+		}
+		sb.WriteString(quoteLiteral(key))
+	}
+	return sb.String()
+}
+
+// JSONContains matches the rows where the jsonb col contains v marshalled to JSON, col @> v, which can use a GIN
+// index on col.
+func JSONContains(col string, v any) query.Option {
+	return query.Where(col, "@>", query.Arg(NewJSONB(v)))
+}
+
+// JSONPath matches the rows where the jsonpath predicate holds for the jsonb col, col @@ path, e.g.
+// JSONPath("meta", "$.logins > 10").
+func JSONPath(col string, path string) query.Option {
+	return query.Where(col, "@@", query.Arg(path))
+}
@@ -1,7 +1,6 @@
 package database
 
 import (
-	"fmt"
 	"sync"
 )
 
@@ -26,7 +25,7 @@ func (kv *InMemoryKV[K, V]) Get(key K) (V, error) {
 	if val, ok := kv.data[key]; ok {
 		return val, nil
 	}
-	return *new(V), fmt.Errorf("not found")
+	return *new(V), ErrNotFound
 }
 
 func (kv *InMemoryKV[K, V]) Delete(key K) error {
@@ -18,6 +18,8 @@ import (
 type MongoDB[T any] struct {
 	Client       *mongo.Client
 	DatabaseName string
+	// Retry retries FindByID, Search, All and Count when set.
+	Retry *RetryPolicy
 }
 
 // NewMongoDB takes an env file and returns mongo client
@@ -53,7 +55,7 @@ func (m *MongoDB[T]) Insert(ctx context.Context, collectionName string, document
 	collection := m.Client.Database(m.DatabaseName).Collection(collectionName)
 	_, err := collection.InsertOne(ctx, document)
 	if err != nil {
-		return err
+		return mapMongoError(err)
 	}
 	return nil
 }
@@ -61,9 +63,11 @@ func (m *MongoDB[T]) Insert(ctx context.Context, collectionName string, document
 func (m *MongoDB[T]) FindByID(ctx context.Context, collectionName string, id uuid.UUID) (T, error) {
 	collection := m.Client.Database(m.DatabaseName).Collection(collectionName)
 
-	var document T
-	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&document)
-	return document, err
+	return retry(ctx, m.Retry, func(ctx context.Context) (T, error) {
+		var document T
+		err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&document)
+		return document, mapMongoError(err)
+	})
 }
 
 func (m *MongoDB[T]) Search(ctx context.Context, collectionName string, filter bson.D, opts *options.FindOptions) ([]T, error) {
@@ -71,46 +75,40 @@ func (m *MongoDB[T]) Search(ctx context.Context, collectionName string, filter b
 	if filter == nil {
 		return results, errors.New("filter cannot be nil")
 	}
-	collection := m.Client.Database(m.DatabaseName).Collection(collectionName)
-	cursor, err := collection.Find(ctx, filter, opts)
-	if err != nil {
-		return results, err
-	}
-	defer cursor.Close(ctx)
-
-	err = cursor.All(ctx, &results)
-	if err != nil {
-		fmt.Println("WTF", err)
-		return results, err
-	}
-
-	return results, nil
+	return m.find(ctx, collectionName, filter, opts)
 }
 
-func (m *MongoDB[T]) All(ctx context.Context, collectionName string, opts *options.FindOptions) ([]T, error) {
-	results := make([]T, 0)
-	filter := bson.D{{}}
+// find decodes every document matching filter, retrying with m.Retry.
+func (m *MongoDB[T]) find(ctx context.Context, collectionName string, filter bson.D, opts *options.FindOptions) ([]T, error) {
 	collection := m.Client.Database(m.DatabaseName).Collection(collectionName)
-	cursor, err := collection.Find(ctx, filter, opts)
-	if err != nil {
-		return results, err
-	}
-	defer cursor.Close(ctx)
 
-	err = cursor.All(ctx, &results)
-	if err != nil {
-		return results, err
-	}
+	return retry(ctx, m.Retry, func(ctx context.Context) ([]T, error) {
+		results := make([]T, 0)
+		cursor, err := collection.Find(ctx, filter, opts)
+		if err != nil {
+			return results, mapMongoError(err)
+		}
+		defer cursor.Close(ctx)
+
+		err = cursor.All(ctx, &results)
+		if err != nil {
+			return results, mapMongoError(err)
+		}
+
+		return results, nil
+	})
+}
 
-	return results, nil
+func (m *MongoDB[T]) All(ctx context.Context, collectionName string, opts *options.FindOptions) ([]T, error) {
+	return m.find(ctx, collectionName, bson.D{{}}, opts)
 }
 
 func (m *MongoDB[T]) Update(ctx context.Context, collectionName string, id uuid.UUID, document T) (*mongo.UpdateResult, error) {
 	collection := m.Client.Database(m.DatabaseName).Collection(collectionName)
 
-	filter := bson.D{{"_id", id}}
+	filter := bson.D{{Key: "_id", Value: id}}
 	//update := bson.D{{"$set", bson.D{{"email", "newemail@example.com"}}}}
-	update := bson.D{{"$set", document}}
+	update := bson.D{{Key: "$set", Value: document}}
 	result, err := collection.UpdateOne(
 		ctx,
 		filter,
@@ -118,7 +116,11 @@ func (m *MongoDB[T]) Update(ctx context.Context, collectionName string, id uuid.
 	)
 
 	if err != nil {
-		return nil, err
+		return nil, mapMongoError(err)
+	}
+
+	if result.MatchedCount == 0 {
+		return result, ErrNotFound
 	}
 
 	return result, nil
@@ -126,9 +128,12 @@ func (m *MongoDB[T]) Update(ctx context.Context, collectionName string, id uuid.
 
 func (m *MongoDB[T]) Delete(ctx context.Context, collectionName string, filter bson.D, opts *options.DeleteOptions) error {
 	collection := m.Client.Database(m.DatabaseName).Collection(collectionName)
-	_, err := collection.DeleteOne(ctx, filter, opts)
+	result, err := collection.DeleteOne(ctx, filter, opts)
 	if err != nil {
-		return err
+		return mapMongoError(err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
 	}
 	return nil
 }
@@ -142,5 +147,10 @@ func (m *MongoDB[T]) Drop(ctx context.Context, collectionName string) error {
 }
 
 func (m *MongoDB[T]) Count(ctx context.Context, collectionName string) (int64, error) {
-	return m.Client.Database(m.DatabaseName).Collection(collectionName).EstimatedDocumentCount(ctx)
+	collection := m.Client.Database(m.DatabaseName).Collection(collectionName)
+
+	return retry(ctx, m.Retry, func(ctx context.Context) (int64, error) {
+		n, err := collection.EstimatedDocumentCount(ctx)
+		return n, mapMongoError(err)
+	})
 }
@@ -0,0 +1,197 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+const (
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Notification is a message received on a channel the repository is subscribed to.
+type Notification struct {
+	Channel string
+	Payload string
+	// PID is the process id of the backend that sent the notification.
+	PID uint32
+}
+
+// Change is the payload sent by the trigger installed with InstallNotifyTrigger.
+type Change struct {
+	// Op is INSERT, UPDATE or DELETE.
+	Op  string          `json:"op"`
+	Key json.RawMessage `json:"key"`
+}
+
+// Change decodes the payload of a notification sent by the trigger installed with InstallNotifyTrigger.
+func (n Notification) Change() (Change, error) {
+	var c Change
+	err := json.Unmarshal([]byte(n.Payload), &c)
+	return c, err
+}
+
+type subscribeOptions struct {
+	onError func(error)
+}
+
+type SubscribeOption func(*subscribeOptions)
+
+// OnListenError calls fn with the error of a lost connection and of every failed attempt to listen again, which
+// are otherwise dropped. fn is called from the goroutine delivering the notifications.
+func OnListenError(fn func(error)) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.onError = fn
+	}
+}
+
+// Subscribe listens on channel using a dedicated connection from the pool and delivers notifications until ctx is
+// done, after which the returned channel is closed. If the connection is lost it is re-established with an
+// exponential backoff, notifications sent while reconnecting are lost.
+func (p PosgresDB[M]) Subscribe(ctx context.Context, channel string, opts ...SubscribeOption) (<-chan Notification, error) {
+	o := subscribeOptions{onError: func(error) {}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	conn, err := p.listen(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Notification)
+
+	go func() {
+		defer close(ch)
+
+		delay := minReconnectDelay
+
+		for {
+			if conn != nil {
+				err = deliver(ctx, conn, ch)
+				closeConn(conn)
+				conn = nil
+
+				if ctx.Err() != nil {
+					return
+				}
+				o.onError(errors.Wrapf(err, "listen on %s", channel))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			if conn, err = p.listen(ctx, channel); err != nil {
+				o.onError(errors.Wrapf(err, "listen on %s", channel))
+				if delay *= 2; delay > maxReconnectDelay {
+					delay = maxReconnectDelay
+				}
+				continue
+			}
+			delay = minReconnectDelay
+		}
+	}()
+	return ch, nil
+}
+
+func (p PosgresDB[M]) listen(ctx context.Context, channel string) (*pgxpool.Conn, error) {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return nil, mapPostgresError(err)
+	}
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		closeConn(conn)
+		return nil, mapPostgresError(err)
+	}
+	return conn, nil
+}
+
+// deliver sends notifications received on conn to ch until ctx is done or the connection fails.
+func deliver(ctx context.Context, conn *pgxpool.Conn, ch chan<- Notification) error {
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case ch <- Notification{Channel: n.Channel, Payload: n.Payload, PID: n.PID}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// closeConn closes a listening connection before releasing it, so the pool discards it instead of handing out a
+// connection that is still subscribed.
+func closeConn(conn *pgxpool.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_ = conn.Conn().Close(ctx)
+	conn.Release()
+}
+
+// InstallNotifyTrigger creates a trigger that sends a notification on channel for every insert, update and
+// delete on the table. The payload is a JSON encoded Change holding the primary key of the row.
+func (p PosgresDB[M]) InstallNotifyTrigger(ctx context.Context, channel string) error {
+	primary, _ := p.new().Primary()
+
+	name := strings.ReplaceAll(p.table, ".", "_") + "_notify"
+	table := identifier(p.table).Sanitize()
+	fn := pgx.Identifier{name}.Sanitize()
+
+	if schema := strings.LastIndex(p.table, "."); schema >= 0 {
+		fn = pgx.Identifier{p.table[:schema], name}.Sanitize()
+	}
+
+	sql := fmt.Sprintf(`CREATE OR REPLACE FUNCTION %[1]s() RETURNS trigger AS $$
+DECLARE
+	r record;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		r := OLD;
+	ELSE
+		r := NEW;
+	END IF;
+	PERFORM pg_notify(%[2]s, json_build_object('op', TG_OP, 'key', r.%[3]s)::text);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS %[4]s ON %[5]s;
+
+CREATE TRIGGER %[4]s AFTER INSERT OR UPDATE OR DELETE ON %[5]s
+FOR EACH ROW EXECUTE FUNCTION %[1]s();`,
+		fn,
+		quoteLiteral(channel),
+		pgx.Identifier{primary}.Sanitize(),
+		pgx.Identifier{name}.Sanitize(),
+		table,
+	)
+
+	_, err := p.conn(ctx).Exec(ctx, sql)
+	return err
+}
+
+// quoteLiteral quotes s as a SQL string literal. A ? is written as an escape, the query builder would otherwise
+// take it for a placeholder of its arguments.
+func quoteLiteral(s string) string {
+	if !strings.Contains(s, "?") {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+
+	s = strings.NewReplacer(`\`, `\\`, "'", "''", "?", `\x3f`).Replace(s)
+	return "E'" + s + "'"
+}
@@ -0,0 +1,222 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/andrewpillar/query"
+	"github.com/pkg/errors"
+	"github.com/themodelarchitect/data/structures"
+	"reflect"
+	"time"
+)
+
+// ErrInvalidCursor is returned by Page when the cursor of a PageRequest cannot be decoded, or was returned for a
+// request of the other pagination mode.
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// PageRequest describes the page to fetch. The first page is requested with an empty Cursor, following pages
+// with the NextCursor of the previous Page.
+type PageRequest struct {
+	// Limit is the maximum number of items in the page.
+	Limit int64
+	// Cursor is the opaque cursor returned by the previous page.
+	Cursor string
+	// Keyset uses seek pagination on SortColumn and the primary key instead of LIMIT/OFFSET.
+	Keyset bool
+	// SortColumn the page is ordered by, defaults to the primary key of the Model.
+	SortColumn string
+	// Desc orders the page in descending order.
+	Desc bool
+	// Options are additional filters applied to the select.
+	Options []query.Option
+}
+
+// Page is a single page of models.
+type Page[M Model] struct {
+	Items *structures.Array[M]
+	// NextCursor is empty when there are no more pages.
+	NextCursor string
+}
+
+type cursor struct {
+	Offset int64    `json:"o,omitempty"`
+	Values []string `json:"v,omitempty"`
+}
+
+func (c cursor) encode() (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err = json.Unmarshal(b, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// cursorValue returns the text representation of v, which postgres parses back into the column type. A NULL has
+// none, since it cannot be compared with the values of the next page.
+func cursorValue(v any) (string, error) {
+	for rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer; rv = rv.Elem() {
+		if rv.IsNil() {
+			return "", errors.New("value is NULL")
+		}
+		v = rv.Elem().Interface()
+	}
+
+	if valuer, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = valuer.Value(); err != nil {
+			return "", err
+		}
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "", errors.New("value is NULL")
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case []byte:
+		return string(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// Page returns a single page of models along with the cursor of the next page.
+func (p PosgresDB[M]) Page(ctx context.Context, req PageRequest) (Page[M], error) {
+	return retry(ctx, p.retry, func(ctx context.Context) (Page[M], error) {
+		return p.page(ctx, p.reader(ctx), req)
+	})
+}
+
+func (p PosgresDB[M]) page(ctx context.Context, db querier, req PageRequest) (Page[M], error) {
+	var page Page[M]
+
+	if req.Limit <= 0 {
+		return page, errors.New("page limit must be greater than zero")
+	}
+
+	var c cursor
+	if req.Cursor != "" {
+		var err error
+		if c, err = decodeCursor(req.Cursor); err != nil {
+			return page, err
+		}
+	}
+
+	// a cursor of the other mode would silently restart at the first page.
+	if (req.Keyset && c.Offset > 0) || (!req.Keyset && len(c.Values) > 0) {
+		return page, ErrInvalidCursor
+	}
+
+	primary, _ := p.new().Primary()
+
+	sortCol := req.SortColumn
+	if sortCol == "" {
+		sortCol = primary
+	}
+
+	order := []string{sortCol}
+	if sortCol != primary {
+		order = append(order, primary)
+	}
+
+	opts := append([]query.Option{}, req.Options...)
+
+	if req.Keyset {
+		if len(c.Values) > 0 {
+			if len(c.Values) != len(order) {
+				return page, ErrInvalidCursor
+			}
+
+			op := ">"
+			if req.Desc {
+				op = "<"
+			}
+
+			args := make([]any, 0, len(c.Values))
+			for _, v := range c.Values {
+				args = append(args, v)
+			}
+
+			col := sortCol
+			if len(order) > 1 {
+				col = "(" + sortCol + ", " + primary + ")"
+			}
+			opts = append(opts, query.Where(col, op, query.List(args...)))
+		}
+	} else if c.Offset > 0 {
+		opts = append(opts, query.Offset(c.Offset))
+	}
+
+	if req.Desc {
+		opts = append(opts, query.OrderDesc(order...))
+	} else {
+		opts = append(opts, query.OrderAsc(order...))
+	}
+
+	// fetch one more than the limit to know if there is a next page.
+	opts = append(opts, query.Limit(req.Limit+1))
+
+	items, err := p.selectModels(ctx, db, []string{"*"}, opts...)
+	if err != nil {
+		return page, err
+	}
+
+	if int64(items.Length()) <= req.Limit {
+		page.Items = items
+		return page, nil
+	}
+
+	items.Pop()
+	page.Items = items
+
+	next := cursor{Offset: c.Offset + req.Limit}
+
+	if req.Keyset {
+		last := items.Lookup(items.Length() - 1)
+		_, key := last.Primary()
+
+		next = cursor{}
+
+		vals := []any{key}
+
+		if sortCol != primary {
+			v, ok := last.Params()[sortCol]
+			if !ok {
+				return page, fmt.Errorf("sort column %q is not a param of the model", sortCol)
+			}
+			vals = []any{v, key}
+		}
+
+		// keyset pagination needs a NOT NULL sort column, or Options excluding the rows where it is NULL.
+		for i, v := range vals {
+			s, err := cursorValue(v)
+			if err != nil {
+				return page, errors.Wrapf(err, "cursor of %s", order[i])
+			}
+			next.Values = append(next.Values, s)
+		}
+	}
+
+	if page.NextCursor, err = next.encode(); err != nil {
+		return page, err
+	}
+	return page, nil
+}
@@ -0,0 +1,185 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ReflectModel implements Model for any struct using its db struct tags.
+/*
+type User struct {
+	Id        uuid.UUID `db:"id,pk,readonly"`
+	Email     string    `db:"email"`
+	FirstName string    // untagged fields use the snake case field name, first_name
+	Password  string    `db:"-"`
+}
+
+users, err := NewPostgresDB("users", NewReflectModel[User])
+*/
+// The tag options are pk for the primary key column and readonly for columns left out of Params, such as ones
+// generated by the database.
+type ReflectModel[T any] struct {
+	Value *T
+}
+
+// NewReflectModel returns a ReflectModel for a new T, it can be used as the new func of NewPostgresDB.
+func NewReflectModel[T any]() ReflectModel[T] {
+	return ReflectModel[T]{Value: new(T)}
+}
+
+// Reflect wraps v as a Model.
+func Reflect[T any](v *T) ReflectModel[T] {
+	return ReflectModel[T]{Value: v}
+}
+
+func (r ReflectModel[T]) Primary() (string, any) {
+	return ReflectPrimary(r.Value)
+}
+
+func (r ReflectModel[T]) Scan(fields []string, scan ScanFunc) error {
+	return ReflectScan(r.Value, fields, scan)
+}
+
+func (r ReflectModel[T]) Params() map[string]any {
+	return ReflectParams(r.Value)
+}
+
+type structField struct {
+	column   string
+	index    []int
+	pk       bool
+	readonly bool
+}
+
+type structInfo struct {
+	fields []structField
+	pk     *structField
+}
+
+var structInfos sync.Map
+
+func typeInfo(t reflect.Type) *structInfo {
+	if info, ok := structInfos.Load(t); ok {
+		return info.(*structInfo)
+	}
+
+	info := &structInfo{}
+	collectFields(t, nil, info)
+
+	for i := range info.fields {
+		if info.fields[i].pk {
+			info.pk = &info.fields[i]
+			break
+		}
+	}
+
+	actual, _ := structInfos.LoadOrStore(t, info)
+	return actual.(*structInfo)
+}
+
+func collectFields(t reflect.Type, index []int, info *structInfo) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("db")
+
+		if tag == "-" {
+			continue
+		}
+
+		idx := append(append([]int{}, index...), i)
+
+		if f.Anonymous && !tagged && f.Type.Kind() == reflect.Struct {
+			collectFields(f.Type, idx, info)
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		opts := strings.Split(tag, ",")
+
+		field := structField{column: opts[0], index: idx}
+		if field.column == "" {
+			field.column = SnakeCase(f.Name)
+		}
+
+		for _, opt := range opts[1:] {
+			switch opt {
+			case "pk":
+				field.pk = true
+			case "readonly":
+				field.readonly = true
+			}
+		}
+		info.fields = append(info.fields, field)
+	}
+}
+
+// SnakeCase converts a Go field name such as FirstName or UserID to first_name or user_id, the column of a field
+// without a name in its db tag.
+func SnakeCase(s string) string {
+	runes := []rune(s)
+
+	var b strings.Builder
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func structValue(v any) reflect.Value {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("database: expected a pointer to a struct, got %T", v))
+	}
+	return rv.Elem()
+}
+
+// ReflectPrimary returns the column and value of the field tagged pk in the struct pointed to by v.
+func ReflectPrimary(v any) (string, any) {
+	rv := structValue(v)
+	info := typeInfo(rv.Type())
+
+	if info.pk == nil {
+		return "", nil
+	}
+	return info.pk.column, rv.FieldByIndex(info.pk.index).Interface()
+}
+
+// ReflectScan scans the given fields into the struct pointed to by v.
+func ReflectScan(v any, fields []string, scan ScanFunc) error {
+	rv := structValue(v)
+	info := typeInfo(rv.Type())
+
+	structMap := make(map[string]any, len(info.fields))
+	for _, f := range info.fields {
+		structMap[f.column] = rv.FieldByIndex(f.index).Addr().Interface()
+	}
+	return Scan(structMap, fields, scan)
+}
+
+// ReflectParams returns the columns of the struct pointed to by v that are not readonly.
+func ReflectParams(v any) map[string]any {
+	rv := structValue(v)
+	info := typeInfo(rv.Type())
+
+	params := make(map[string]any, len(info.fields))
+	for _, f := range info.fields {
+		if f.readonly {
+			continue
+		}
+		params[f.column] = rv.FieldByIndex(f.index).Interface()
+	}
+	return params
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/andrewpillar/query"
+	"github.com/themodelarchitect/data/structures"
+)
+
+// inChunk is the number of keys sent in a single IN (...) list.
+const inChunk = 10_000
+
+// Relation is a relation of M that can be eager loaded by Preload. Keys are compared with ==, so key values
+// must be comparable, e.g. uuid.UUID, int64 or string.
+type Relation[M Model] interface {
+	load(ctx context.Context, models []M) error
+}
+
+type hasMany[P Model, C Model] struct {
+	children PosgresDB[C]
+	fk       string
+	attach   func(parent P, children []C)
+}
+
+// HasMany declares that the rows of children reference the primary key of P through the fk column. Preload calls
+// attach once for every parent, with an empty slice for parents without children.
+func HasMany[P Model, C Model](children PosgresDB[C], fk string, attach func(parent P, children []C)) Relation[P] {
+	return hasMany[P, C]{children: children, fk: fk, attach: attach}
+}
+
+func (r hasMany[P, C]) load(ctx context.Context, parents []P) error {
+	if len(parents) == 0 {
+		return nil
+	}
+
+	keys := make([]any, 0, len(parents))
+	for _, parent := range parents {
+		_, key := parent.Primary()
+		keys = append(keys, key)
+	}
+
+	byParent := make(map[any][]C, len(parents))
+
+	err := r.children.in(ctx, r.fk, keys, func(child C) error {
+		key, ok := child.Params()[r.fk]
+		if !ok {
+			return fmt.Errorf("foreign key %q is not a param of the child model", r.fk)
+		}
+		byParent[key] = append(byParent[key], child)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, parent := range parents {
+		_, key := parent.Primary()
+		r.attach(parent, byParent[key])
+	}
+	return nil
+}
+
+type belongsTo[C Model, P Model] struct {
+	parents PosgresDB[P]
+	fk      string
+	attach  func(child C, parent P)
+}
+
+// BelongsTo declares that the fk column of C references the primary key of the rows of parents. Preload calls
+// attach for every child whose parent was found.
+func BelongsTo[C Model, P Model](parents PosgresDB[P], fk string, attach func(child C, parent P)) Relation[C] {
+	return belongsTo[C, P]{parents: parents, fk: fk, attach: attach}
+}
+
+func (r belongsTo[C, P]) load(ctx context.Context, children []C) error {
+	if len(children) == 0 {
+		return nil
+	}
+
+	seen := make(map[any]bool, len(children))
+	keys := make([]any, 0, len(children))
+
+	for _, child := range children {
+		key, ok := child.Params()[r.fk]
+		if !ok {
+			return fmt.Errorf("foreign key %q is not a param of the child model", r.fk)
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	pk, _ := r.parents.new().Primary()
+	byKey := make(map[any]P, len(keys))
+
+	err := r.parents.in(ctx, pk, keys, func(parent P) error {
+		_, key := parent.Primary()
+		byKey[key] = parent
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if parent, ok := byKey[child.Params()[r.fk]]; ok {
+			r.attach(child, parent)
+		}
+	}
+	return nil
+}
+
+// in calls fn for every model whose col is one of keys, querying at most inChunk keys at a time.
+func (p PosgresDB[M]) in(ctx context.Context, col string, keys []any, fn func(M) error) error {
+	for start := 0; start < len(keys); start += inChunk {
+		end := start + inChunk
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		err := p.iterate(ctx, p.reader(ctx), []string{"*"}, fn, query.Where(col, "IN", query.List(keys[start:end]...)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Preload eager loads the given relations for every model, with one query per relation.
+func (p PosgresDB[M]) Preload(ctx context.Context, models *structures.Array[M], relations ...Relation[M]) error {
+	values := models.Values()
+
+	for _, r := range relations {
+		if err := r.load(ctx, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
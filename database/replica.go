@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReplicaStrategy picks the replica a read is sent to.
+type ReplicaStrategy int
+
+const (
+	// RoundRobin cycles through the healthy replicas.
+	RoundRobin ReplicaStrategy = iota
+	// LeastLoaded picks the healthy replica with the fewest acquired connections.
+	LeastLoaded
+)
+
+const defaultHealthCheckInterval = 5 * time.Second
+
+// WithReplicas sends Select, All, Get and the other reads to the given replica pools, while writes and
+// transactions stay on the primary. Replicas are pinged every interval and skipped while unhealthy, reads go to
+// the primary when no replica is healthy.
+func WithReplicas(strategy ReplicaStrategy, interval time.Duration, replicas ...*pgxpool.Pool) PostgresOption {
+	return func(o *postgresOptions) {
+		o.replicas = replicas
+		o.strategy = strategy
+		o.healthCheckInterval = interval
+	}
+}
+
+type usePrimaryKey struct{}
+
+// UsePrimary returns a context under which reads are sent to the primary, e.g. to read your own writes.
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, usePrimaryKey{}, true)
+}
+
+func usePrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(usePrimaryKey{}).(bool)
+	return v
+}
+
+type replicaSet struct {
+	pools    []*pgxpool.Pool
+	healthy  []atomic.Bool
+	strategy ReplicaStrategy
+	next     atomic.Uint64
+	stop     chan struct{}
+	once     sync.Once
+}
+
+func newReplicaSet(pools []*pgxpool.Pool, strategy ReplicaStrategy, interval time.Duration) *replicaSet {
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+
+	rs := &replicaSet{
+		pools:    pools,
+		healthy:  make([]atomic.Bool, len(pools)),
+		strategy: strategy,
+		stop:     make(chan struct{}),
+	}
+
+	for i := range rs.healthy {
+		rs.healthy[i].Store(true)
+	}
+
+	go rs.check(interval)
+	return rs
+}
+
+// check pings every replica each interval until the set is closed.
+func (rs *replicaSet) check(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rs.stop:
+			return
+		case <-ticker.C:
+		}
+
+		for i, pool := range rs.pools {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			rs.healthy[i].Store(pool.Ping(ctx) == nil)
+			cancel()
+		}
+	}
+}
+
+// pick returns a healthy replica, or nil if there is none.
+func (rs *replicaSet) pick() *pgxpool.Pool {
+	n := len(rs.pools)
+
+	switch rs.strategy {
+	case LeastLoaded:
+		var best *pgxpool.Pool
+		var load int32
+
+		for i, pool := range rs.pools {
+			if !rs.healthy[i].Load() {
+				continue
+			}
+			if l := pool.Stat().AcquiredConns(); best == nil || l < load {
+				best, load = pool, l
+			}
+		}
+		return best
+	default:
+		start := rs.next.Add(1)
+
+		for i := 0; i < n; i++ {
+			idx := int((start + uint64(i)) % uint64(n))
+			if rs.healthy[idx].Load() {
+				return rs.pools[idx]
+			}
+		}
+		return nil
+	}
+}
+
+// close stops the health checks and closes the replica pools.
+func (rs *replicaSet) close() {
+	rs.once.Do(func() {
+		close(rs.stop)
+		for _, pool := range rs.pools {
+			pool.Close()
+		}
+	})
+}
+
+// reader returns the querier reads outside of a transaction run on.
+func (p PosgresDB[M]) reader(ctx context.Context) querier {
+	if p.replicas == nil || usePrimary(ctx) {
+		return p.conn(ctx)
+	}
+	if pool := p.replicas.pick(); pool != nil {
+		return p.wrap(p.session(ctx, pool))
+	}
+	return p.conn(ctx)
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"github.com/jackc/pgconn"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// RetryPolicy retries transient errors with exponential backoff and jitter.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the delay before the second attempt, it doubles with every attempt up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Jitter is the fraction, between 0 and 1, of every delay that is randomised.
+	Jitter float64
+	// Retryable classifies errors, IsRetryable is used when nil.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy makes up to 3 attempts, backing off from 50ms.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Jitter:         0.5,
+	}
+}
+
+// WithRetry retries the reads of the repository, Select, All, Get, Page and the aggregates, using policy.
+// Writes are never retried automatically since they may not be idempotent, wrap them in policy.Do instead.
+func WithRetry(policy RetryPolicy) PostgresOption {
+	return func(o *postgresOptions) {
+		o.retry = &policy
+	}
+}
+
+// retryable postgres error codes and classes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+var retryableCodes = map[string]bool{
+	pgSerializationFailure: true,
+	pgDeadlockDetected:     true,
+	"57P01":                true, // admin_shutdown
+	"57P02":                true, // crash_shutdown
+	"57P03":                true, // cannot_connect_now
+	"53300":                true, // too_many_connections
+}
+
+// IsRetryable reports whether err is a transient error: serialization failures, deadlocks, connection failures,
+// and mongo errors labelled as retryable or transient.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// class 08 is connection exceptions.
+		return retryableCodes[pgErr.Code] || strings.HasPrefix(pgErr.Code, "08")
+	}
+
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	if mongo.IsNetworkError(err) {
+		return true
+	}
+
+	var le mongo.LabeledError
+	if errors.As(err, &le) {
+		return le.HasErrorLabel("RetryableWriteError") || le.HasErrorLabel("TransientTransactionError")
+	}
+	return false
+}
+
+// Do calls fn until it succeeds, returns an error that is not retryable, the attempts are exhausted or ctx is
+// done. fn must be safe to call more than once, e.g. a whole WithTx call.
+func (r RetryPolicy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	retryable := r.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	backoff := r.InitialBackoff
+
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil || attempt >= r.MaxAttempts || !retryable(err) {
+			return err
+		}
+
+		delay := backoff
+		if r.Jitter > 0 {
+			delay -= time.Duration(r.Jitter * rand.Float64() * float64(backoff))
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+
+		if backoff *= 2; r.MaxBackoff > 0 && backoff > r.MaxBackoff {
+			backoff = r.MaxBackoff
+		}
+	}
+}
+
+// retry calls fn using policy, or once if policy is nil.
+func retry[T any](ctx context.Context, policy *RetryPolicy, fn func(ctx context.Context) (T, error)) (T, error) {
+	if policy == nil {
+		return fn(ctx)
+	}
+
+	var v T
+
+	err := policy.Do(ctx, func(ctx context.Context) error {
+		var err error
+		v, err = fn(ctx)
+		return err
+	})
+	return v, err
+}
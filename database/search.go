@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/andrewpillar/query"
+	"strconv"
+	"strings"
+)
+
+// defaultSearchConfig is the text search configuration used when none is given.
+const defaultSearchConfig = "english"
+
+// SearchRequest describes a full-text search. Either Columns or Vector must be set.
+type SearchRequest struct {
+	// Query is the user input, parsed with websearch_to_tsquery so it supports "quoted phrases", or and -negation.
+	Query string
+	// Columns are the text columns searched, ignored if Vector is set.
+	Columns []string
+	// Vector is a tsvector column, e.g. one generated from the searched columns.
+	Vector string
+	// Config is the text search configuration, english when empty. It must match the one of the index.
+	Config string
+	// Highlight is the column snippets are built from, the first of Columns when empty. Matches are wrapped in
+	// <b></b>.
+	Highlight string
+	Limit     int64
+	// Options filter the rows searched.
+	Options []query.Option
+}
+
+// SearchResult is a Model matching a search, with its rank and highlighted snippet.
+type SearchResult[M Model] struct {
+	Model   M
+	Rank    float64
+	Snippet string
+}
+
+// tsvector returns the expression the columns are searched through, SearchIndex creates the index on the same
+// expression so the planner can use it.
+func tsvector(config string, cols []string) string {
+	parts := make([]string, 0, len(cols))
+	for _, col := range cols {
+		parts = append(parts, "coalesce("+col+", '')")
+	}
+	return "to_tsvector(" + quoteLiteral(config) + ", " + strings.Join(parts, " || ' ' || ") + ")"
+}
+
+// sql selects the matches of r from the rows of from, param is the placeholder of the query.
+func (r SearchRequest) sql(from string, param string) (string, error) {
+	config := r.Config
+	if config == "" {
+		config = defaultSearchConfig
+	}
+
+	vector := r.Vector
+	if vector == "" {
+		if len(r.Columns) == 0 {
+			return "", fmt.Errorf("search has neither columns nor a vector")
+		}
+		vector = tsvector(config, r.Columns)
+	}
+
+	highlight := r.Highlight
+	if highlight == "" && len(r.Columns) > 0 {
+		highlight = r.Columns[0]
+	}
+
+	snippet := "''"
+	if highlight != "" {
+		snippet = "ts_headline(" + quoteLiteral(config) + ", coalesce(" + highlight + ", ''), search_query)"
+	}
+
+	return "SELECT t.*, ts_rank(" + vector + ", search_query) AS search_rank, " + snippet + " AS search_snippet" +
+		" FROM " + from + " AS t, websearch_to_tsquery(" + quoteLiteral(config) + ", " + param + ") AS search_query" +
+		" WHERE " + vector + " @@ search_query ORDER BY search_rank DESC", nil
+}
+
+// Search returns the models matching req, best ranked first.
+func (p PosgresDB[M]) Search(ctx context.Context, req SearchRequest) ([]SearchResult[M], error) {
+	return retry(ctx, p.retry, func(ctx context.Context) ([]SearchResult[M], error) {
+		return p.search(ctx, p.reader(ctx), req)
+	})
+}
+
+func (p PosgresDB[M]) search(ctx context.Context, db querier, req SearchRequest) ([]SearchResult[M], error) {
+	from, args := p.selectQuery(ctx, query.Columns("*"), req.Options...)
+	args = append(args, req.Query)
+
+	sql, err := req.sql("("+from+")", "$"+strconv.Itoa(len(args)))
+	if err != nil {
+		return nil, err
+	}
+	if req.Limit > 0 {
+		sql += " LIMIT " + strconv.FormatInt(req.Limit, 10)
+	}
+
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var flds []string
+	results := make([]SearchResult[M], 0)
+
+	for rows.Next() {
+		if flds == nil {
+			flds = fields(rows)
+			// the rank and snippet are scanned alongside the fields of the model.
+			flds = flds[:len(flds)-2]
+		}
+
+		r := SearchResult[M]{Model: p.new()}
+
+		err = r.Model.Scan(flds, func(dest ...any) error {
+			return rows.Scan(append(dest, &r.Rank, &r.Snippet)...)
+		})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// SearchIndex returns a migration creating a GIN index over the tsvector of cols, for searches with the same
+// table, config and columns.
+func SearchIndex(version int64, table string, config string, cols ...string) Migration {
+	if config == "" {
+		config = defaultSearchConfig
+	}
+
+	name := strings.ReplaceAll(table, ".", "_") + "_" + strings.Join(cols, "_") + "_search_idx"
+
+	// the index is created in the schema of the table.
+	qualified := name
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		qualified = table[:i+1] + name
+	}
+
+	return Migration{
+		Version: version,
+		Name:    "create_" + name,
+		Up:      "CREATE INDEX IF NOT EXISTS " + name + " ON " + table + " USING GIN (" + tsvector(config, cols) + ")",
+		Down:    "DROP INDEX IF EXISTS " + qualified,
+	}
+}
@@ -0,0 +1,234 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"strconv"
+	"strings"
+)
+
+// WithSchema runs the statements of the repository with schema first in the search_path, so a bare table name
+// resolves to the table of that schema.
+func WithSchema(schema string) PostgresOption {
+	return func(o *postgresOptions) {
+		o.schema = schema
+	}
+}
+
+// ForSchema returns a copy of the repository running its statements with schema first in the search_path, e.g.
+// to serve a schema per tenant with a single repository.
+func (p PosgresDB[M]) ForSchema(schema string) PosgresDB[M] {
+	p.schema = schema
+	return p
+}
+
+type schemaKey struct{}
+
+// UseSchema returns a context under which statements run with schema first in the search_path, overriding the
+// schema of the repository.
+func UseSchema(ctx context.Context, schema string) context.Context {
+	return context.WithValue(ctx, schemaKey{}, schema)
+}
+
+// searchPath returns the search_path statements run with, or an empty string to keep the default. public stays
+// on the path so shared tables and extensions still resolve.
+func (p PosgresDB[M]) searchPath(ctx context.Context) string {
+	schema := p.schema
+	if v, ok := ctx.Value(schemaKey{}).(string); ok {
+		schema = v
+	}
+
+	if schema == "" {
+		return ""
+	}
+	return pgx.Identifier{schema}.Sanitize() + ", public"
+}
+
+// setting is a run-time parameter set with set_config for the duration of a transaction.
+type setting struct {
+	name  string
+	value string
+}
+
+// settings returns the parameters the statements of the repository run with.
+func (p PosgresDB[M]) settings(ctx context.Context) []setting {
+	var settings []setting
+
+	if path := p.searchPath(ctx); path != "" {
+		settings = append(settings, setting{name: "search_path", value: path})
+	}
+	if p.tenant != nil && p.tenant.SessionVar != "" {
+		settings = append(settings, setting{name: p.tenant.SessionVar, value: fmt.Sprint(p.tenant.ID)})
+	}
+	return settings
+}
+
+// session returns pool, or a querier applying the settings of the repository around every statement on pool.
+func (p PosgresDB[M]) session(ctx context.Context, pool *pgxpool.Pool) querier {
+	settings := p.settings(ctx)
+	if len(settings) == 0 {
+		return pool
+	}
+	return sessionQuerier{pool: pool, settings: settings}
+}
+
+// setSession applies the settings of the repository for the rest of tx.
+func (p PosgresDB[M]) setSession(ctx context.Context, tx pgx.Tx) error {
+	return setConfig(ctx, tx, p.settings(ctx))
+}
+
+func setConfig(ctx context.Context, tx pgx.Tx, settings []setting) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	calls := make([]string, 0, len(settings))
+	args := make([]any, 0, len(settings)*2)
+
+	for i, s := range settings {
+		calls = append(calls, "set_config($"+strconv.Itoa(2*i+1)+", $"+strconv.Itoa(2*i+2)+", true)")
+		args = append(args, s.name, s.value)
+	}
+
+	_, err := tx.Exec(ctx, "SELECT "+strings.Join(calls, ", "), args...)
+	return err
+}
+
+// sessionQuerier runs every statement in its own transaction that first applies the settings, since a setting
+// made with is_local only lasts until the end of the transaction and connections are shared through the pool.
+type sessionQuerier struct {
+	pool     *pgxpool.Pool
+	settings []setting
+}
+
+func (q sessionQuerier) begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := q.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = setConfig(ctx, tx, q.settings); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (q sessionQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx, err := q.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return tag, tx.Commit(ctx)
+}
+
+func (q sessionQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	tx, err := q.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return &sessionRows{Rows: rows, ctx: ctx, tx: tx}, nil
+}
+
+func (q sessionQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	tx, err := q.begin(ctx)
+	if err != nil {
+		return failedRow{err}
+	}
+	return sessionRow{row: tx.QueryRow(ctx, sql, args...), ctx: ctx, tx: tx}
+}
+
+func (q sessionQuerier) CopyFrom(ctx context.Context, table pgx.Identifier, cols []string, src pgx.CopyFromSource) (int64, error) {
+	tx, err := q.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := tx.CopyFrom(ctx, table, cols, src)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return 0, err
+	}
+	return n, tx.Commit(ctx)
+}
+
+// sessionRows ends the transaction of a query once its rows are read or closed.
+type sessionRows struct {
+	pgx.Rows
+	ctx  context.Context
+	tx   pgx.Tx
+	err  error
+	done bool
+}
+
+func (r *sessionRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.finish()
+	return false
+}
+
+func (r *sessionRows) Close() {
+	r.finish()
+}
+
+func (r *sessionRows) Err() error {
+	if err := r.Rows.Err(); err != nil {
+		return err
+	}
+	return r.err
+}
+
+func (r *sessionRows) finish() {
+	if r.done {
+		return
+	}
+	r.done = true
+
+	r.Rows.Close()
+
+	if r.Rows.Err() != nil {
+		_ = r.tx.Rollback(r.ctx)
+		return
+	}
+	r.err = r.tx.Commit(r.ctx)
+}
+
+// sessionRow ends the transaction of a query once its row is scanned.
+type sessionRow struct {
+	row pgx.Row
+	ctx context.Context
+	tx  pgx.Tx
+}
+
+func (r sessionRow) Scan(dest ...any) error {
+	if err := r.row.Scan(dest...); err != nil {
+		_ = r.tx.Rollback(r.ctx)
+		return err
+	}
+	return r.tx.Commit(r.ctx)
+}
+
+type failedRow struct {
+	err error
+}
+
+func (r failedRow) Scan(dest ...any) error {
+	return r.err
+}
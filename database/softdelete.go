@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"github.com/andrewpillar/query"
+	"strings"
+	"time"
+)
+
+// Timestamped models have their created and updated times set by Create and Update before Params is called.
+type Timestamped interface {
+	SetCreatedAt(t time.Time)
+	SetUpdatedAt(t time.Time)
+}
+
+// SoftDeletable models are marked as deleted by Delete instead of being removed, and are left out of Select,
+// All, Get and the other reads unless the context was returned by IncludeDeleted.
+type SoftDeletable interface {
+	// DeletedAtColumn returns the name of the column set when the model is deleted, e.g. deleted_at.
+	DeletedAtColumn() string
+}
+
+type includeDeletedKey struct{}
+
+// IncludeDeleted returns a context under which reads also return soft deleted models.
+func IncludeDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+func includeDeleted(ctx context.Context) bool {
+	v, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return v
+}
+
+// now is the clock used for timestamps, UTC to match the timestamp without time zone columns.
+var now = func() time.Time {
+	return time.Now().UTC()
+}
+
+func touchCreated(m Model, t time.Time) {
+	if ts, ok := m.(Timestamped); ok {
+		ts.SetCreatedAt(t)
+		ts.SetUpdatedAt(t)
+	}
+}
+
+func touchUpdated(m Model, t time.Time) {
+	if ts, ok := m.(Timestamped); ok {
+		ts.SetUpdatedAt(t)
+	}
+}
+
+// deletedAtColumn returns the soft delete column of M, or an empty string if M is not SoftDeletable.
+func (p PosgresDB[M]) deletedAtColumn() string {
+	if sd, ok := any(p.new()).(SoftDeletable); ok {
+		return sd.DeletedAtColumn()
+	}
+	return ""
+}
+
+// predicate is a condition every statement of a scoped repository is restricted by.
+type predicate struct {
+	col  string
+	op   string
+	expr query.Expr
+}
+
+// String returns the condition with ? as the placeholder of its arguments.
+func (pred predicate) String() string {
+	return pred.col + " " + pred.op + " " + pred.expr.Build()
+}
+
+// scope returns the predicates every read, update and delete of the repository is restricted by.
+func (p PosgresDB[M]) scope(ctx context.Context) []predicate {
+	preds := p.tenantScope()
+
+	if col := p.deletedAtColumn(); col != "" && !includeDeleted(ctx) {
+		preds = append(preds, predicate{col: col, op: "IS", expr: query.Lit("NULL")})
+	}
+	return preds
+}
+
+// whereOptions returns preds as WHERE options. They may only be mixed with AND-ed conditions, the query builder
+// does not group the conditions of an OrWhere, use filter to scope the caller's options.
+func whereOptions(preds []predicate) []query.Option {
+	opts := make([]query.Option, 0, len(preds))
+
+	for _, pred := range preds {
+		opts = append(opts, query.Where(pred.col, pred.op, pred.expr))
+	}
+	return opts
+}
+
+// from returns the FROM clause of reads along with its arguments. When the repository is scoped the table is
+// wrapped in a derived table, so the scope is applied regardless of the order and conjunction of the caller's
+// options. The FROM clause comes before any other placeholder of a select, its arguments go first.
+func (p PosgresDB[M]) from(ctx context.Context) (query.Option, []any) {
+	preds := p.scope(ctx)
+	if len(preds) == 0 {
+		return query.From(p.table), nil
+	}
+
+	conds := make([]string, 0, len(preds))
+	args := make([]any, 0, len(preds))
+
+	for _, pred := range preds {
+		conds = append(conds, pred.String())
+		args = append(args, pred.expr.Args()...)
+	}
+
+	alias := p.table[strings.LastIndex(p.table, ".")+1:]
+
+	return query.From("(SELECT * FROM " + p.table + " WHERE " + strings.Join(conds, " AND ") + ") AS " + alias), args
+}
+
+// selectQuery returns the SQL and arguments of a select of expr from the rows of the repository matching opts.
+func (p PosgresDB[M]) selectQuery(ctx context.Context, expr query.Expr, opts ...query.Option) (string, []any) {
+	from, args := p.from(ctx)
+
+	q := query.Select(expr, append([]query.Option{from}, opts...)...)
+
+	return q.Build(), append(args, q.Args()...)
+}
+
+// filter returns the WHERE options of a bulk update or delete. The caller's conditions are grouped in a sub-select
+// of the primary key, so the scope AND-ed after it holds whatever their conjunctions.
+func (p PosgresDB[M]) filter(ctx context.Context, opts []query.Option) []query.Option {
+	scope := whereOptions(p.scope(ctx))
+	if len(opts) == 0 {
+		return scope
+	}
+
+	pk, _ := p.new().Primary()
+
+	sub := query.Select(query.Columns(pk), append([]query.Option{query.From(p.table)}, opts...)...)
+
+	return append([]query.Option{query.Where(pk, "IN", sub)}, scope...)
+}
+
+// HardDelete removes m from the table even if it is SoftDeletable.
+func (p PosgresDB[M]) HardDelete(ctx context.Context, m M) error {
+	return p.hardDelete(ctx, p.conn(ctx), m)
+}
+
+func (p PosgresDB[M]) hardDelete(ctx context.Context, db querier, m M) error {
+	col, id := m.Primary()
+
+	opts := append([]query.Option{
+		query.Where(col, "=", query.Arg(id)),
+	}, whereOptions(p.tenantScope())...)
+
+	q := query.Delete(p.table, opts...)
+
+	tag, err := db.Exec(ctx, q.Build(), q.Args()...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p PosgresDB[M]) softDelete(ctx context.Context, db querier, m M, deletedAt string) error {
+	col, id := m.Primary()
+
+	opts := append([]query.Option{
+		query.Set(deletedAt, query.Arg(now())),
+		query.Where(col, "=", query.Arg(id)),
+		query.Where(deletedAt, "IS", query.Lit("NULL")),
+	}, whereOptions(p.tenantScope())...)
+
+	q := query.Update(p.table, opts...)
+
+	tag, err := db.Exec(ctx, q.Build(), q.Args()...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgconn/stmtcache"
+	"github.com/pkg/errors"
+	"sync/atomic"
+)
+
+// StatementCache prepares every statement on first use on a connection and reuses it for the same SQL, so hot
+// queries such as Get by id are parsed and planned once per connection. The SQL built from the same options is
+// identical whatever the arguments, so it serves as the key. Set it on PostgresConfig, a single StatementCache
+// can be shared by several pools and reports their combined stats.
+type StatementCache struct {
+	size int
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+	seq       atomic.Uint64
+}
+
+// StatementCacheStats are the counters of a StatementCache since it was created.
+type StatementCacheStats struct {
+	Hits int64 `json:"hits"`
+	// Misses is the number of statements prepared.
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+}
+
+// NewStatementCache returns a cache keeping at most size statements prepared per connection, the least recently
+// used statement is deallocated to make room for a new one.
+func NewStatementCache(size int) *StatementCache {
+	if size < 1 {
+		size = 1
+	}
+	return &StatementCache{size: size}
+}
+
+// Stats returns the counters of the cache.
+func (c *StatementCache) Stats() StatementCacheStats {
+	return StatementCacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
+// build returns the cache of a single connection, as set on pgx.ConnConfig.BuildStatementCache.
+func (c *StatementCache) build(conn *pgconn.PgConn) stmtcache.Cache {
+	return &connCache{
+		conn:   conn,
+		shared: c,
+		prefix: fmt.Sprintf("stmt_%d", c.seq.Add(1)),
+		m:      make(map[string]*list.Element),
+		l:      list.New(),
+	}
+}
+
+// connCache is the LRU of the statements prepared on one connection. A connection is used by one goroutine at a
+// time, only the shared counters need to be atomic.
+type connCache struct {
+	conn   *pgconn.PgConn
+	shared *StatementCache
+	prefix string
+	count  int
+	m      map[string]*list.Element
+	l      *list.List
+	// invalid statements are deallocated once the connection is out of a failed transaction.
+	invalid []string
+}
+
+var _ stmtcache.Cache = (*connCache)(nil)
+
+func (c *connCache) Get(ctx context.Context, sql string) (*pgconn.StatementDescription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if status := c.conn.TxStatus(); (status == 'I' || status == 'T') && len(c.invalid) > 0 {
+		for _, sql := range c.invalid {
+			if el, ok := c.m[sql]; ok {
+				if err := c.remove(ctx, el); err != nil {
+					return nil, err
+				}
+			}
+		}
+		c.invalid = nil
+	}
+
+	if el, ok := c.m[sql]; ok {
+		c.shared.hits.Add(1)
+		c.l.MoveToFront(el)
+		return el.Value.(*pgconn.StatementDescription), nil
+	}
+
+	c.shared.misses.Add(1)
+
+	if c.l.Len() >= c.shared.size {
+		if err := c.remove(ctx, c.l.Back()); err != nil {
+			return nil, err
+		}
+		c.shared.evictions.Add(1)
+	}
+
+	name := fmt.Sprintf("%s_%d", c.prefix, c.count)
+	c.count++
+
+	sd, err := c.conn.Prepare(ctx, name, sql, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.m[sql] = c.l.PushFront(sd)
+	return sd, nil
+}
+
+func (c *connCache) Clear(ctx context.Context) error {
+	for c.l.Len() > 0 {
+		if err := c.remove(ctx, c.l.Back()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StatementErrored marks the statement as invalid when its cached plan may no longer match the table, e.g.
+// after a column was added and SELECT * returns a new result type.
+func (c *connCache) StatementErrored(sql string, err error) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "0A000" {
+		c.invalid = append(c.invalid, sql)
+	}
+}
+
+func (c *connCache) Len() int {
+	return c.l.Len()
+}
+
+func (c *connCache) Cap() int {
+	return c.shared.size
+}
+
+func (c *connCache) Mode() int {
+	return stmtcache.ModePrepare
+}
+
+// remove deallocates the statement of el.
+func (c *connCache) remove(ctx context.Context, el *list.Element) error {
+	sd := c.l.Remove(el).(*pgconn.StatementDescription)
+	delete(c.m, sd.SQL)
+
+	return c.conn.Exec(ctx, "DEALLOCATE "+sd.Name).Close()
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"github.com/andrewpillar/query"
+)
+
+// Tenant identifies the customer a repository is scoped to.
+type Tenant struct {
+	// Column holds the tenant of every row, e.g. tenant_id.
+	Column string
+	ID     any
+	// SessionVar, e.g. app.tenant_id, is set to ID with set_config for every statement when not empty, so row
+	// level security policies can use current_setting('app.tenant_id').
+	SessionVar string
+}
+
+// ForTenant returns a copy of the repository restricted to the rows of t. Reads, updates and deletes only see
+// the rows whose t.Column is t.ID, and Create, CreateMany, InsertMany and Upsert set t.Column to t.ID.
+/*
+acme := users.ForTenant(Tenant{Column: "tenant_id", ID: acmeID})
+u, ok, err := acme.Get(ctx, query.Where("email", "=", query.Arg(email)))
+*/
+func (p PosgresDB[M]) ForTenant(t Tenant) PosgresDB[M] {
+	p.tenant = &t
+	return p
+}
+
+// tenantScope returns the tenant predicate of the repository, if it is scoped to a tenant.
+func (p PosgresDB[M]) tenantScope() []predicate {
+	if p.tenant == nil {
+		return nil
+	}
+	return []predicate{{col: p.tenant.Column, op: "=", expr: query.Arg(p.tenant.ID)}}
+}
+
+// tenantColumn returns the tenant column of the repository, or an empty string if it is not scoped to a tenant.
+func (p PosgresDB[M]) tenantColumn() string {
+	if p.tenant == nil {
+		return ""
+	}
+	return p.tenant.Column
+}
+
+// params returns the params of m with the tenant column set, if the repository is scoped to a tenant.
+func (p PosgresDB[M]) params(m M) map[string]any {
+	params := m.Params()
+	if p.tenant == nil {
+		return params
+	}
+
+	stamped := make(map[string]any, len(params)+1)
+	for k, v := range params {
+		stamped[k] = v
+	}
+	stamped[p.tenant.Column] = p.tenant.ID
+	return stamped
+}
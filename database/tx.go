@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/andrewpillar/query"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/themodelarchitect/data/structures"
+)
+
+// TxDB exposes the PosgresDB operations bound to a single transaction.
+type TxDB[M Model] struct {
+	pgx.Tx
+	db PosgresDB[M]
+}
+
+type TxOption func(*pgx.TxOptions)
+
+// Isolation sets the isolation level of the transaction, e.g. pgx.Serializable.
+func Isolation(level pgx.TxIsoLevel) TxOption {
+	return func(opts *pgx.TxOptions) {
+		opts.IsoLevel = level
+	}
+}
+
+// ReadOnly starts the transaction in READ ONLY access mode.
+func ReadOnly() TxOption {
+	return func(opts *pgx.TxOptions) {
+		opts.AccessMode = pgx.ReadOnly
+	}
+}
+
+// Deferrable starts the transaction as DEFERRABLE, only meaningful for serializable read only transactions.
+func Deferrable() TxOption {
+	return func(opts *pgx.TxOptions) {
+		opts.DeferrableMode = pgx.Deferrable
+	}
+}
+
+// WithTx begins a transaction and calls fn with it. The transaction is committed if fn returns nil and rolled
+// back if fn returns an error or panics, in which case the panic is re-raised after the rollback.
+func (p PosgresDB[M]) WithTx(ctx context.Context, fn func(tx TxDB[M]) error, opts ...TxOption) (err error) {
+	var txOpts pgx.TxOptions
+	for _, opt := range opts {
+		opt(&txOpts)
+	}
+
+	tx, err := p.BeginTx(ctx, txOpts)
+	if err != nil {
+		return mapPostgresError(err)
+	}
+
+	if err = p.setSession(ctx, tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return mapPostgresError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback(ctx)
+			panic(r)
+		}
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil {
+				err = fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+			}
+			return
+		}
+		err = mapPostgresError(tx.Commit(ctx))
+	}()
+
+	return fn(p.Tx(tx))
+}
+
+// Tx binds the repository to an already open transaction, so several repositories can take part in the same
+// transaction started by WithTx. The schema and the tenant session variable of the
+// repository are only applied by WithTx.
+func (p PosgresDB[M]) Tx(tx pgx.Tx) TxDB[M] {
+	return TxDB[M]{Tx: tx, db: p}
+}
+
+// conn returns the querier statements of the transaction run on.
+func (t TxDB[M]) conn() querier {
+	return t.db.wrap(t.Tx)
+}
+
+// Create a new entity M within the transaction and return the primary key.
+func (t TxDB[M]) Create(ctx context.Context, m M) (any, error) {
+	return t.db.create(ctx, t.conn(), m)
+}
+
+func (t TxDB[M]) Update(ctx context.Context, m M) error {
+	return t.db.update(ctx, t.conn(), m)
+}
+
+func (t TxDB[M]) CreateColumns(ctx context.Context, m M, cols ...string) (any, error) {
+	return t.db.create(ctx, t.conn(), m, cols...)
+}
+
+func (t TxDB[M]) UpdateColumns(ctx context.Context, m M, cols ...string) error {
+	if len(cols) == 0 {
+		return errors.New("update requires at least one column")
+	}
+	return t.db.update(ctx, t.conn(), m, cols...)
+}
+
+func (t TxDB[M]) Delete(ctx context.Context, m M) error {
+	return t.db.delete(ctx, t.conn(), m)
+}
+
+func (t TxDB[M]) Select(ctx context.Context, cols []string, opts ...query.Option) (*structures.Array[M], error) {
+	return t.db.selectModels(ctx, t.conn(), cols, opts...)
+}
+
+func (t TxDB[M]) All(ctx context.Context) (*structures.Array[M], error) {
+	return t.db.all(ctx, t.conn())
+}
+
+func (t TxDB[M]) Get(ctx context.Context, opts ...query.Option) (M, bool, error) {
+	return t.db.get(ctx, t.conn(), opts...)
+}
+
+func (t TxDB[M]) CreateMany(ctx context.Context, models []M) (int64, error) {
+	return t.db.createMany(ctx, t.conn(), models)
+}
+
+func (t TxDB[M]) InsertMany(ctx context.Context, models []M) error {
+	return t.db.insertMany(ctx, t.conn(), models)
+}
+
+func (t TxDB[M]) Upsert(ctx context.Context, m M, conflictCols []string, updateCols []string) (bool, error) {
+	return t.db.upsert(ctx, t.conn(), m, conflictCols, updateCols)
+}
+
+func (t TxDB[M]) Rows(ctx context.Context, cols []string, opts ...query.Option) (*Iterator[M], error) {
+	return t.db.iterator(ctx, t.conn(), cols, opts...)
+}
+
+func (t TxDB[M]) Iterate(ctx context.Context, cols []string, fn func(M) error, opts ...query.Option) error {
+	return t.db.iterate(ctx, t.conn(), cols, fn, opts...)
+}
+
+func (t TxDB[M]) Page(ctx context.Context, req PageRequest) (Page[M], error) {
+	return t.db.page(ctx, t.conn(), req)
+}
+
+func (t TxDB[M]) HardDelete(ctx context.Context, m M) error {
+	return t.db.hardDelete(ctx, t.conn(), m)
+}
+
+func (t TxDB[M]) Count(ctx context.Context, opts ...query.Option) (int64, error) {
+	return t.db.count(ctx, t.conn(), opts...)
+}
+
+func (t TxDB[M]) Exists(ctx context.Context, opts ...query.Option) (bool, error) {
+	return t.db.exists(ctx, t.conn(), opts...)
+}
+
+func (t TxDB[M]) UpdateWhere(ctx context.Context, set map[string]any, opts ...query.Option) (int64, error) {
+	return t.db.updateWhere(ctx, t.conn(), set, opts...)
+}
+
+func (t TxDB[M]) DeleteWhere(ctx context.Context, opts ...query.Option) (int64, error) {
+	return t.db.deleteWhere(ctx, t.conn(), opts...)
+}
+
+func (t TxDB[M]) Search(ctx context.Context, req SearchRequest) ([]SearchResult[M], error) {
+	return t.db.search(ctx, t.conn(), req)
+}
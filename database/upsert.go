@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"github.com/andrewpillar/query"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// Upsert inserts m, or updates updateCols of the existing row when the insert conflicts on conflictCols. If
+// updateCols is empty the conflict is ignored with DO NOTHING. The written row is scanned back into m, false is
+// returned when nothing was written because of DO NOTHING.
+func (p PosgresDB[M]) Upsert(ctx context.Context, m M, conflictCols []string, updateCols []string) (bool, error) {
+	return p.upsert(ctx, p.conn(ctx), m, conflictCols, updateCols)
+}
+
+func (p PosgresDB[M]) upsert(ctx context.Context, db querier, m M, conflictCols []string, updateCols []string) (bool, error) {
+	if len(conflictCols) == 0 {
+		return false, errors.New("upsert requires at least one conflict column")
+	}
+
+	touchCreated(m, now())
+	params := p.params(m)
+	cols := columns(params)
+	vals := make([]any, 0, len(cols))
+
+	for _, col := range cols {
+		vals = append(vals, params[col])
+	}
+
+	q := query.Insert(p.table, query.Columns(cols...), query.Values(vals...))
+	args := q.Args()
+
+	var buf strings.Builder
+
+	buf.WriteString(q.Build())
+	buf.WriteString(" ON CONFLICT (" + strings.Join(conflictCols, ", ") + ")")
+
+	if len(updateCols) == 0 {
+		buf.WriteString(" DO NOTHING")
+	} else {
+		set := make([]string, 0, len(updateCols))
+		for _, col := range updateCols {
+			set = append(set, col+" = EXCLUDED."+col)
+		}
+		buf.WriteString(" DO UPDATE SET " + strings.Join(set, ", "))
+
+		// the conflicting row may belong to another tenant, it is then left alone.
+		if preds := p.tenantScope(); len(preds) > 0 {
+			args = append(args, p.tenant.ID)
+			buf.WriteString(" WHERE " + preds[0].col + " " + preds[0].op + " $" + strconv.Itoa(len(args)))
+		}
+	}
+	buf.WriteString(" RETURNING *")
+
+	rows, err := db.Query(ctx, buf.String(), args...)
+	if err != nil {
+		return false, err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		return false, rows.Err()
+	}
+
+	if err = m.Scan(fields(rows), rows.Scan); err != nil {
+		return false, err
+	}
+
+	// closing the rows may still fail, e.g. when the statement runs in its own transaction and the commit fails.
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
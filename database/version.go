@@ -0,0 +1,31 @@
+package database
+
+import (
+	"github.com/andrewpillar/query"
+	"github.com/pkg/errors"
+)
+
+// ErrStaleObject is returned by Update when a Versioned model was changed since it was read, it is an ErrConflict.
+var ErrStaleObject = errors.Wrap(ErrConflict, "stale object")
+
+// Versioned models are updated with optimistic concurrency control. Update only writes the row if its version
+// column still matches the version of the model, and increments the version on write.
+type Versioned interface {
+	// Version returns the name of the version column and the version the model was read at.
+	Version() (string, int64)
+	SetVersion(version int64)
+}
+
+// versionOptions returns the SET option that increments the version of m and the WHERE option that checks it.
+// The version column is removed from params so it is not written twice.
+func versionOptions(m Model, params map[string]any) (set query.Option, where query.Option, ok bool) {
+	v, ok := m.(Versioned)
+	if !ok {
+		return nil, nil, false
+	}
+
+	col, version := v.Version()
+	delete(params, col)
+
+	return query.Set(col, query.Lit(col+" + 1")), query.Where(col, "=", query.Arg(version)), true
+}
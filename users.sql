@@ -1,6 +1,6 @@
 CREATE TABLE users (
-   id uuid DEFAULT gen_random_uuid() ,
-   email character varying(255),
+   id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+   email character varying(255) UNIQUE,
    first_name character varying(255),
    last_name character varying(255),
    password character varying(60),